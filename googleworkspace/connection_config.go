@@ -1,14 +1,26 @@
 package googleworkspace
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
 type googleworkspaceConfig struct {
-	CredentialFile        *string `hcl:"credential_file"`
-	Credentials           *string `hcl:"credentials"`
-	ImpersonatedUserEmail *string `hcl:"impersonated_user_email"`
-	TokenPath             *string `hcl:"token_path"`
+	CredentialFile        *string           `hcl:"credential_file"`
+	Credentials           *string           `hcl:"credentials"`
+	ImpersonatedUserEmail *string           `hcl:"impersonated_user_email"`
+	TokenPath             *string           `hcl:"token_path"`
+	CustomerID            *string           `hcl:"customer_id"`
+	Domains               []string          `hcl:"domains,optional"`
+	MaxRetries            *int              `hcl:"max_retries"`
+	MinRetryDelay         *int              `hcl:"min_retry_delay"`
+	EndpointOverrides     map[string]string `hcl:"endpoint_overrides,optional"`
+	HTTPProxy             *string           `hcl:"http_proxy"`
+	CABundle              *string           `hcl:"ca_bundle"`
+	ViewType              *string           `hcl:"view_type"`
 }
 
 func ConfigInstance() interface{} {
@@ -23,3 +35,43 @@ func GetConfig(connection *plugin.Connection) googleworkspaceConfig {
 	config, _ := connection.Config.(googleworkspaceConfig)
 	return config
 }
+
+// getCustomerID returns the customer ID configured for the connection, or the
+// `my_customer` alias for the account of the authenticated user
+func getCustomerID(d *plugin.QueryData) string {
+	googleworkspaceConfig := GetConfig(d.Connection)
+	if googleworkspaceConfig.CustomerID != nil && *googleworkspaceConfig.CustomerID != "" {
+		return *googleworkspaceConfig.CustomerID
+	}
+	return "my_customer"
+}
+
+// getDomains returns the domains to list Directory resources for. The `domain` qual takes
+// precedence over the configured domains. An empty string means all domains of the customer.
+func getDomains(d *plugin.QueryData) []string {
+	if domain := d.EqualsQualString("domain"); domain != "" {
+		return []string{domain}
+	}
+
+	googleworkspaceConfig := GetConfig(d.Connection)
+	if len(googleworkspaceConfig.Domains) > 0 {
+		return googleworkspaceConfig.Domains
+	}
+
+	return []string{""}
+}
+
+// Services whose endpoint can be overridden using endpoint_overrides
+var endpointOverrideServices = []string{"admin", "calendar", "drive", "gmail", "groupssettings", "iamcredentials", "people"}
+
+// getEndpointOverride returns the endpoint configured for the given service, or an empty
+// string to use the default endpoint
+func getEndpointOverride(d *plugin.QueryData, service string) (string, error) {
+	googleworkspaceConfig := GetConfig(d.Connection)
+	for name := range googleworkspaceConfig.EndpointOverrides {
+		if !slices.Contains(endpointOverrideServices, name) {
+			return "", fmt.Errorf("invalid endpoint_overrides service %q: must be one of %s", name, strings.Join(endpointOverrideServices, ", "))
+		}
+	}
+	return googleworkspaceConfig.EndpointOverrides[service], nil
+}
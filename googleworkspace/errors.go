@@ -0,0 +1,47 @@
+package googleworkspace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// isIgnorableFanOutError returns true if an error returned by a per-entity API call, made while
+// fanning out a listing, does not mean that the listing is incomplete
+func isIgnorableFanOutError(err error) bool {
+	// The query was cancelled, or its limit has been hit
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		switch gerr.Code {
+		// The entity was deleted after it was listed
+		case http.StatusNotFound:
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNotAdminError returns true if the error is returned by an Admin SDK call that requires administrator
+// privileges the caller does not have. Other forbidden errors, such as rate limits, disabled APIs or
+// missing scopes, have a different reason.
+func isNotAdminError(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr.Code != http.StatusForbidden || len(gerr.Errors) == 0 {
+		return false
+	}
+
+	for _, item := range gerr.Errors {
+		if item.Reason != "forbidden" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+package googleworkspace
+
+import (
+	"context"
+	"sync"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// fanOutConcurrency is the maximum number of per-entity API calls, e.g. listing the
+// tokens of each user, that run concurrently when fanning out a listing
+const fanOutConcurrency = 10
+
+// fanOutPool runs per-entity API calls using a bounded number of concurrent workers
+type fanOutPool struct {
+	ctx context.Context
+	d   *plugin.QueryData
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+func newFanOutPool(ctx context.Context, d *plugin.QueryData) *fanOutPool {
+	return &fanOutPool{
+		ctx: ctx,
+		d:   d,
+		sem: make(chan struct{}, fanOutConcurrency),
+	}
+}
+
+// Go runs fn as soon as a worker is available. It returns false without running fn
+// once the query needs no more rows, so that callers can stop paging.
+func (p *fanOutPool) Go(fn func()) bool {
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.ctx.Done():
+		return false
+	}
+
+	if p.d.RowsRemaining(p.ctx) == 0 {
+		<-p.sem
+		return false
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		fn()
+	}()
+
+	return true
+}
+
+// Wait blocks until all scheduled calls have completed
+func (p *fanOutPool) Wait() {
+	p.wg.Wait()
+}
+
+// fanOutUsers calls fn concurrently for each user of the domains to list, while paging through
+// the users. Only the ID and primary email of each user are retrieved.
+func fanOutUsers(ctx context.Context, d *plugin.QueryData, service *admin.Service, fn func(user *admin.User)) error {
+	pool := newFanOutPool(ctx, d)
+	defer pool.Wait()
+
+	userFields := googleapi.Field("nextPageToken,users(id,primaryEmail)")
+
+	for _, domain := range getDomains(d) {
+		usersReq := service.Users.List().Fields(userFields).MaxResults(500)
+		if domain != "" {
+			usersReq = usersReq.Domain(domain)
+		} else {
+			usersReq = usersReq.Customer(getCustomerID(d))
+		}
+
+		err := usersReq.Pages(ctx, func(page *admin.Users) error {
+			// Apply the list rate limiters to each page, before the next one is requested
+			d.WaitForListRateLimit(ctx)
+
+			for _, user := range page.Users {
+				if !pool.Go(func() { fn(user) }) {
+					page.NextPageToken = ""
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
+	}
+
+	return nil
+}
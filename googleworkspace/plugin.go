@@ -11,6 +11,7 @@ import (
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"github.com/turbot/steampipe-plugin-sdk/v5/rate_limiter"
 )
 
 const pluginName = "steampipe-plugin-googleworkspace"
@@ -26,28 +27,110 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
 		},
-		TableMap: map[string]*plugin.Table{
-			"googleworkspace_calendar":                tableGoogleWorkspaceCalendar(ctx),
-			"googleworkspace_calendar_event":          tableGoogleWorkspaceCalendarEvent(ctx),
-			"googleworkspace_calendar_my_event":       tableGoogleWorkspaceCalendarMyEvent(ctx),
-			"googleworkspace_drive":                   tableGoogleWorkspaceDrive(ctx),
-			"googleworkspace_drive_my_file":           tableGoogleWorkspaceDriveMyFile(ctx),
-			"googleworkspace_gmail_draft":             tableGoogleWorkspaceGmailDraft(ctx),
-			"googleworkspace_gmail_message":           tableGoogleWorkspaceGmailMessage(ctx),
-			"googleworkspace_gmail_my_draft":          tableGoogleWorkspaceGmailMyDraft(ctx),
-			"googleworkspace_gmail_my_message":        tableGoogleWorkspaceGmailMyMessage(ctx),
-			"googleworkspace_gmail_my_settings":       tableGoogleWorkspaceGmailMySettings(ctx),
-			"googleworkspace_gmail_settings":          tableGoogleWorkspaceGmailSettings(ctx),
-			"googleworkspace_people_contact":          tableGoogleWorkspacePeopleContact(ctx),
-			"googleworkspace_people_contact_group":    tableGoogleWorkspacePeopleContactGroup(ctx),
-			"googleworkspace_people_directory_people": tableGoogleWorkspacePeopleDirectoryPeople(ctx),
-			"googleworkspace_directory_users":         tableGoogleWorkspaceDirectoryUsers(ctx),
-			"googleworkspace_tokens_list":             tableGoogleWorkspaceTokensList(ctx),
-			"googleworkspace_orgunits":                tableGoogleWorkspaceOrgUnits(ctx),
-			"googleworkspace_groups":                  tableGoogleWorkspaceGroups(ctx),
-			"googleworkspace_group_members":           tableGoogleWorkspaceGroupMembers(ctx),
+		// Default rate limiters per Google API, based on the default per-user quotas of each API.
+		// Every list, get and hydrate config is tagged with the `service` it calls, and whether
+		// it is a `list` or `get` action. Limiters can be overridden using `limiter` blocks in HCL.
+		RateLimiters: []*rate_limiter.Definition{
+			{
+				// Admin SDK Directory API: 2,400 queries per minute per user
+				Name:       "googleworkspace_admin",
+				FillRate:   40,
+				BucketSize: 100,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'admin'",
+			},
+			{
+				// Calendar API: 600 queries per minute per user
+				Name:       "googleworkspace_calendar",
+				FillRate:   10,
+				BucketSize: 50,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'calendar'",
+			},
+			{
+				// Drive API: 12,000 queries per minute per user
+				Name:       "googleworkspace_drive",
+				FillRate:   100,
+				BucketSize: 100,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'drive'",
+			},
+			{
+				// Gmail API: 250 quota units per second per user, where most reads cost 5 units
+				Name:       "googleworkspace_gmail",
+				FillRate:   50,
+				BucketSize: 50,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'gmail'",
+			},
+			{
+				// Groups Settings API: settings are fetched once per group, so stay well below the
+				// Admin SDK Directory API limiter that pages through the groups
+				Name:       "googleworkspace_groupssettings",
+				FillRate:   10,
+				BucketSize: 50,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'groupssettings'",
+			},
+			{
+				// People API: 90 read requests per minute per user
+				Name:       "googleworkspace_people",
+				FillRate:   1.5,
+				BucketSize: 50,
+				Scope:      []string{"connection", "service"},
+				Where:      "service = 'people'",
+			},
 		},
+		// Tables are built per connection, since a table is added for each custom user schema
+		SchemaMode:   plugin.SchemaModeDynamic,
+		TableMapFunc: pluginTableDefinitions,
 	}
 
 	return p
 }
+
+func pluginTableDefinitions(ctx context.Context, d *plugin.TableMapData) (map[string]*plugin.Table, error) {
+	tables := map[string]*plugin.Table{
+		"googleworkspace_calendar":                tableGoogleWorkspaceCalendar(ctx),
+		"googleworkspace_calendar_event":          tableGoogleWorkspaceCalendarEvent(ctx),
+		"googleworkspace_calendar_my_event":       tableGoogleWorkspaceCalendarMyEvent(ctx),
+		"googleworkspace_connection_check":        tableGoogleWorkspaceConnectionCheck(ctx),
+		"googleworkspace_drive":                   tableGoogleWorkspaceDrive(ctx),
+		"googleworkspace_drive_my_file":           tableGoogleWorkspaceDriveMyFile(ctx),
+		"googleworkspace_gmail_draft":             tableGoogleWorkspaceGmailDraft(ctx),
+		"googleworkspace_gmail_message":           tableGoogleWorkspaceGmailMessage(ctx),
+		"googleworkspace_gmail_my_draft":          tableGoogleWorkspaceGmailMyDraft(ctx),
+		"googleworkspace_gmail_my_message":        tableGoogleWorkspaceGmailMyMessage(ctx),
+		"googleworkspace_gmail_my_settings":       tableGoogleWorkspaceGmailMySettings(ctx),
+		"googleworkspace_gmail_settings":          tableGoogleWorkspaceGmailSettings(ctx),
+		"googleworkspace_people_contact":          tableGoogleWorkspacePeopleContact(ctx),
+		"googleworkspace_people_contact_group":    tableGoogleWorkspacePeopleContactGroup(ctx),
+		"googleworkspace_people_directory_people": tableGoogleWorkspacePeopleDirectoryPeople(ctx),
+		"googleworkspace_directory_users":         tableGoogleWorkspaceDirectoryUsers(ctx),
+		"googleworkspace_directory_deleted_user":  tableGoogleWorkspaceDirectoryDeletedUser(ctx),
+		"googleworkspace_tokens_list":             tableGoogleWorkspaceTokensList(ctx),
+		"googleworkspace_orgunits":                tableGoogleWorkspaceOrgUnits(ctx),
+		"googleworkspace_groups":                  tableGoogleWorkspaceGroups(ctx),
+		"googleworkspace_group_members":           tableGoogleWorkspaceGroupMembers(ctx),
+		"googleworkspace_group_settings":          tableGoogleWorkspaceGroupSettings(ctx),
+		"googleworkspace_group_member_transitive": tableGoogleWorkspaceGroupMemberTransitive(ctx),
+		"googleworkspace_chromeos_device":         tableGoogleWorkspaceChromeOSDevice(ctx),
+		"googleworkspace_customer":                tableGoogleWorkspaceCustomer(ctx),
+		"googleworkspace_domain":                  tableGoogleWorkspaceDomain(ctx),
+		"googleworkspace_domain_alias":            tableGoogleWorkspaceDomainAlias(ctx),
+		"googleworkspace_mobile_device":           tableGoogleWorkspaceMobileDevice(ctx),
+		"googleworkspace_role":                    tableGoogleWorkspaceRole(ctx),
+		"googleworkspace_role_assignment":         tableGoogleWorkspaceRoleAssignment(ctx),
+		"googleworkspace_role_privilege":          tableGoogleWorkspaceRolePrivilege(ctx),
+		"googleworkspace_user_asp":                tableGoogleWorkspaceUserAsp(ctx),
+		"googleworkspace_user_schema":             tableGoogleWorkspaceUserSchema(ctx),
+		"googleworkspace_user_verification_code":  tableGoogleWorkspaceUserVerificationCode(ctx),
+	}
+
+	// Add a table for each custom user schema
+	for name, table := range tablesGoogleWorkspaceUserSchemas(ctx, d) {
+		tables[name] = table
+	}
+
+	return tables, nil
+}
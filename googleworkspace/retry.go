@@ -0,0 +1,174 @@
+package googleworkspace
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// defaultMaxRetries is the default number of times a request failing with a transient error is retried
+	defaultMaxRetries = 5
+	// defaultMinRetryDelay is the default delay, in milliseconds, before the first retry
+	defaultMinRetryDelay = 100
+	// maxRetryDelay caps the delay between two retries
+	maxRetryDelay = 30 * time.Second
+)
+
+// HTTP status codes of transient errors returned by Google APIs
+var retryableErrorCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Reasons of transient errors returned by Google APIs. Rate limit errors are
+// returned with a 403 status code by some APIs, e.g. the Admin SDK and Gmail.
+var retryableErrorReasons = []string{
+	"rateLimitExceeded",
+	"userRateLimitExceeded",
+	"backendError",
+	"internalError",
+}
+
+// isRetryableError returns true if the error is a transient Google API error,
+// which may resolve itself when the request is retried later
+func isRetryableError(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+
+	if slices.Contains(retryableErrorCodes, gerr.Code) {
+		return true
+	}
+
+	for _, item := range gerr.Errors {
+		if slices.Contains(retryableErrorReasons, item.Reason) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryTransport retries requests failing with a transient error, using exponential backoff with jitter
+type retryTransport struct {
+	base          http.RoundTripper
+	maxRetries    int
+	minRetryDelay time.Duration
+}
+
+// newRetryTransport returns a retryTransport wrapping base, configured with the
+// max_retries and min_retry_delay of the connection
+func newRetryTransport(d *plugin.QueryData, base http.RoundTripper) (*retryTransport, error) {
+	googleworkspaceConfig := GetConfig(d.Connection)
+
+	maxRetries := defaultMaxRetries
+	if googleworkspaceConfig.MaxRetries != nil {
+		if *googleworkspaceConfig.MaxRetries < 0 {
+			return nil, errors.New("max_retries must be greater than or equal to 0")
+		}
+		maxRetries = *googleworkspaceConfig.MaxRetries
+	}
+
+	minRetryDelay := defaultMinRetryDelay
+	if googleworkspaceConfig.MinRetryDelay != nil {
+		if *googleworkspaceConfig.MinRetryDelay < 1 {
+			return nil, errors.New("min_retry_delay must be greater than or equal to 1")
+		}
+		minRetryDelay = *googleworkspaceConfig.MinRetryDelay
+	}
+
+	return &retryTransport{
+		base:          base,
+		maxRetries:    maxRetries,
+		minRetryDelay: time.Duration(minRetryDelay) * time.Millisecond,
+	}, nil
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !isRetryableResponse(resp) {
+			return resp, err
+		}
+
+		// Requests with a body can only be retried if the body can be read again
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		delay := t.retryDelay(attempt, resp)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRetryableResponse returns true if the response holds a transient Google API error.
+// The response body is left unread for the caller.
+func isRetryableResponse(resp *http.Response) bool {
+	if resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	// Parse the error the same way the API clients do
+	check := *resp
+	check.Body = io.NopCloser(bytes.NewReader(body))
+
+	return isRetryableError(googleapi.CheckResponse(&check))
+}
+
+// retryDelay returns the delay before the given retry attempt, doubling the minimum delay for
+// each attempt and adding jitter. A delay requested by the API using Retry-After is honoured.
+func (t *retryTransport) retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := t.minRetryDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	// Pick a random delay between half and the full delay, so that concurrent requests do not retry in lockstep
+	delay = delay/2 + rand.N(delay/2+1)
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		if retryAfter := time.Duration(seconds) * time.Second; retryAfter > delay {
+			delay = min(retryAfter, maxRetryDelay)
+		}
+	}
+
+	return delay
+}
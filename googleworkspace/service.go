@@ -3,6 +3,10 @@ package googleworkspace
 import (
 	"context"
 	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -10,21 +14,48 @@ import (
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/groupssettings/v1"
 	"google.golang.org/api/option"
 	"google.golang.org/api/people/v1"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 )
 
+// OAuth 2.0 scopes requested by each service
+var (
+	adminScopes = []string{
+		admin.AdminDirectoryUserReadonlyScope,
+		admin.AdminDirectoryOrgunitReadonlyScope,
+		admin.AdminDirectoryUserSecurityScope,
+		admin.AdminDirectoryGroupReadonlyScope,
+		admin.AdminDirectoryGroupMemberReadonlyScope,
+	}
+	calendarScopes = []string{calendar.CalendarReadonlyScope}
+	driveScopes    = []string{drive.DriveReadonlyScope}
+	gmailScopes    = []string{gmail.GmailReadonlyScope}
+	peopleScopes   = []string{people.ContactsReadonlyScope, people.ContactsOtherReadonlyScope, people.DirectoryReadonlyScope}
+
+	// There is no read-only scope for the Groups Settings API
+	groupsSettingsScopes = []string{groupssettings.AppsGroupsSettingsScope}
+)
+
+// Authentication modes, as chosen by getTokenSource from the connection config
+const (
+	authModeDomainWideDelegation = "domain_wide_delegation"
+	authModeOAuthToken           = "oauth_token"
+	authModeApplicationDefault   = "application_default_credentials"
+)
+
 func CalendarService(ctx context.Context, d *plugin.QueryData) (*calendar.Service, error) {
-	// have we already created and cached the service?
-	serviceCacheKey := "googleworkspace.calendar"
+	// have we already created and cached the service for the impersonated user?
+	subject := getImpersonateUser(d)
+	serviceCacheKey := subjectCacheKey("googleworkspace.calendar", subject)
 	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
 		return cachedData.(*calendar.Service), nil
 	}
 
 	// so it was not in cache - create service
-	opts, err := getSessionConfig(ctx, d)
+	opts, err := getSessionConfig(ctx, d, "calendar", subject, calendarScopes...)
 	if err != nil {
 		return nil, err
 	}
@@ -42,14 +73,15 @@ func CalendarService(ctx context.Context, d *plugin.QueryData) (*calendar.Servic
 }
 
 func PeopleService(ctx context.Context, d *plugin.QueryData) (*people.Service, error) {
-	// have we already created and cached the service?
-	serviceCacheKey := "googleworkspace.people"
+	// have we already created and cached the service for the impersonated user?
+	subject := getImpersonateUser(d)
+	serviceCacheKey := subjectCacheKey("googleworkspace.people", subject)
 	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
 		return cachedData.(*people.Service), nil
 	}
 
 	// so it was not in cache - create service
-	opts, err := getSessionConfig(ctx, d)
+	opts, err := getSessionConfig(ctx, d, "people", subject, peopleScopes...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,14 +99,15 @@ func PeopleService(ctx context.Context, d *plugin.QueryData) (*people.Service, e
 }
 
 func DriveService(ctx context.Context, d *plugin.QueryData) (*drive.Service, error) {
-	// have we already created and cached the service?
-	serviceCacheKey := "googleworkspace.drive"
+	// have we already created and cached the service for the impersonated user?
+	subject := getImpersonateUser(d)
+	serviceCacheKey := subjectCacheKey("googleworkspace.drive", subject)
 	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
 		return cachedData.(*drive.Service), nil
 	}
 
 	// so it was not in cache - create service
-	opts, err := getSessionConfig(ctx, d)
+	opts, err := getSessionConfig(ctx, d, "drive", subject, driveScopes...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,14 +125,15 @@ func DriveService(ctx context.Context, d *plugin.QueryData) (*drive.Service, err
 }
 
 func GmailService(ctx context.Context, d *plugin.QueryData) (*gmail.Service, error) {
-	// have we already created and cached the service?
-	serviceCacheKey := "googleworkspace.gmail"
+	// have we already created and cached the service for the impersonated user?
+	subject := getImpersonateUser(d)
+	serviceCacheKey := subjectCacheKey("googleworkspace.gmail", subject)
 	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
 		return cachedData.(*gmail.Service), nil
 	}
 
 	// so it was not in cache - create service
-	opts, err := getSessionConfig(ctx, d)
+	opts, err := getSessionConfig(ctx, d, "gmail", subject, gmailScopes...)
 	if err != nil {
 		return nil, err
 	}
@@ -116,71 +150,160 @@ func GmailService(ctx context.Context, d *plugin.QueryData) (*gmail.Service, err
 	return svc, nil
 }
 
-func getSessionConfig(ctx context.Context, d *plugin.QueryData) ([]option.ClientOption, error) {
-	opts := []option.ClientOption{}
+func GroupsSettingsService(ctx context.Context, d *plugin.QueryData) (*groupssettings.Service, error) {
+	// have we already created and cached the service?
+	serviceCacheKey := "googleworkspace.groupssettings"
+	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
+		return cachedData.(*groupssettings.Service), nil
+	}
+
+	// so it was not in cache - create service
+	opts, err := getSessionConfig(ctx, d, "groupssettings", "", groupsSettingsScopes...)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get credential file path, and user to impersonate from config (if mentioned)
-	var credentialContent, tokenPath string
-	googleworkspaceConfig := GetConfig(d.Connection)
+	// Create service
+	svc, err := groupssettings.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	// 'credential_file' in connection config is DEPRECATED, and will be removed in future release
-	// use `credentials` instead
-	if googleworkspaceConfig.Credentials != nil {
-		credentialContent = *googleworkspaceConfig.Credentials
-	} else if googleworkspaceConfig.CredentialFile != nil {
-		credentialContent = *googleworkspaceConfig.CredentialFile
+	// cache the service
+	d.ConnectionManager.Cache.Set(serviceCacheKey, svc)
+
+	return svc, nil
+}
+
+// getSessionConfig returns the client options for a service, requesting only the given
+// OAuth 2.0 scopes when authenticating using domain-wide delegation.
+// If subject is set, it overrides the impersonated_user_email configured for the connection.
+func getSessionConfig(ctx context.Context, d *plugin.QueryData, service string, subject string, scopes ...string) ([]option.ClientOption, error) {
+	ts, err := getTokenSource(ctx, d, subject, scopes)
+	if err != nil {
+		return nil, err
 	}
 
-	if googleworkspaceConfig.TokenPath != nil {
-		tokenPath = *googleworkspaceConfig.TokenPath
+	base, err := getBaseTransport(d)
+	if err != nil {
+		return nil, err
 	}
 
-	// If credential path provided, use domain-wide delegation
-	if credentialContent != "" {
-		ts, err := getTokenSource(ctx, d)
-		if err != nil {
-			return nil, err
-		}
-		opts = append(opts, option.WithTokenSource(ts))
-		return opts, nil
+	// Retry transient errors, such as rate limit and backend errors, for every service
+	transport, err := newRetryTransport(d, &oauth2.Transport{Source: ts, Base: base})
+	if err != nil {
+		return nil, err
 	}
 
-	// If token path provided, authenticate using OAuth 2.0
-	if tokenPath != "" {
-		path, err := expandPath(tokenPath)
-		if err != nil {
-			return nil, err
-		}
-		opts = append(opts, option.WithCredentialsFile(path))
-		return opts, nil
+	opts := []option.ClientOption{
+		option.WithHTTPClient(&http.Client{Transport: transport}),
 	}
 
-	return nil, nil
-}
+	// Send requests to the endpoint configured for the service, if any
+	endpoint, err := getEndpointOverride(d, service)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
 
-// Returns a JWT TokenSource using the configuration and the HTTP client from the provided context.
-func getTokenSource(ctx context.Context, d *plugin.QueryData) (oauth2.TokenSource, error) {
-	// Note: based on https://developers.google.com/admin-sdk/directory/v1/guides/delegation#go
+	return opts, nil
+}
 
+// Returns a TokenSource for the authentication method configured for the connection.
+// Token sources are cached per subject and scope set, since each service requests its own scopes.
+func getTokenSource(ctx context.Context, d *plugin.QueryData, subject string, scopes []string) (oauth2.TokenSource, error) {
 	// have we already created and cached the token?
-	cacheKey := "googleworkspace.token_source"
+	cacheKey := subjectCacheKey(tokenSourceCacheKey(scopes), subject)
 	if ts, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
 		return ts.(oauth2.TokenSource), nil
 	}
 
-	// Get credential file path, and user to impersonate from config (if mentioned)
-	var impersonateUser string
+	// Send token requests using the proxy and CA certificates configured for the connection
+	ctx, err := withBaseTransport(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Impersonating a user per query is only possible using domain-wide delegation
+	mode := getAuthMode(d)
+	if subject != "" && mode != authModeDomainWideDelegation {
+		return nil, errors.New("impersonate_user requires credentials with domain-wide delegation to be configured")
+	}
+
+	var ts oauth2.TokenSource
+	switch mode {
+	// If credential path provided, use domain-wide delegation
+	case authModeDomainWideDelegation:
+		ts, err = getDelegatedTokenSource(ctx, d, getCredentialContent(d), subject, scopes)
+
+	// If token path provided, authenticate using OAuth 2.0
+	case authModeOAuthToken:
+		ts, err = getOAuthTokenSource(ctx, *GetConfig(d.Connection).TokenPath, scopes)
+
+	// Otherwise, use the application default credentials
+	default:
+		var creds *google.Credentials
+		creds, err = google.FindDefaultCredentials(ctx, scopes...)
+		if err == nil {
+			ts = creds.TokenSource
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// cache the token source
+	d.ConnectionManager.Cache.Set(cacheKey, ts)
+
+	return ts, nil
+}
+
+// getAuthMode returns the authentication mode used by the connection
+func getAuthMode(d *plugin.QueryData) string {
+	googleworkspaceConfig := GetConfig(d.Connection)
+	switch {
+	case getCredentialContent(d) != "":
+		return authModeDomainWideDelegation
+	case googleworkspaceConfig.TokenPath != nil && *googleworkspaceConfig.TokenPath != "":
+		return authModeOAuthToken
+	default:
+		return authModeApplicationDefault
+	}
+}
+
+// getCredentialContent returns the service account credentials configured for the connection, if any
+func getCredentialContent(d *plugin.QueryData) string {
 	googleworkspaceConfig := GetConfig(d.Connection)
 
-	// Read credential from JSON string, or from the given path
-	// NOTE: 'credential_file' in connection config is DEPRECATED, and will be removed in future release
+	// 'credential_file' in connection config is DEPRECATED, and will be removed in future release
 	// use `credentials` instead
-	var creds string
 	if googleworkspaceConfig.Credentials != nil {
-		creds = *googleworkspaceConfig.Credentials
-	} else if googleworkspaceConfig.CredentialFile != nil {
-		creds = *googleworkspaceConfig.CredentialFile
+		return *googleworkspaceConfig.Credentials
 	}
+	if googleworkspaceConfig.CredentialFile != nil {
+		return *googleworkspaceConfig.CredentialFile
+	}
+	return ""
+}
+
+// getDelegatedSubject returns the user impersonated using domain-wide delegation.
+// A user impersonated by the query takes precedence over the configured one.
+func getDelegatedSubject(d *plugin.QueryData, subject string) string {
+	if subject != "" {
+		return subject
+	}
+	googleworkspaceConfig := GetConfig(d.Connection)
+	if googleworkspaceConfig.ImpersonatedUserEmail != nil {
+		return *googleworkspaceConfig.ImpersonatedUserEmail
+	}
+	return ""
+}
+
+// Returns a JWT TokenSource using the configuration and the HTTP client from the provided context.
+func getDelegatedTokenSource(ctx context.Context, d *plugin.QueryData, creds string, subject string, scopes []string) (oauth2.TokenSource, error) {
+	// Note: based on https://developers.google.com/admin-sdk/directory/v1/guides/delegation#go
 
 	// Read credential from JSON string, or from the given path
 	credentialContent, err := pathOrContents(creds)
@@ -188,47 +311,96 @@ func getTokenSource(ctx context.Context, d *plugin.QueryData) (oauth2.TokenSourc
 		return nil, err
 	}
 
-	if googleworkspaceConfig.ImpersonatedUserEmail != nil {
-		impersonateUser = *googleworkspaceConfig.ImpersonatedUserEmail
-	}
+	impersonateUser := getDelegatedSubject(d, subject)
 
 	// Return error, since impersonation required to authenticate using domain-wide delegation
 	if impersonateUser == "" {
 		return nil, errors.New("impersonated_user_email must be configured")
 	}
 
-	//Added only necessary scopes for the Admin SDK Directory service
-	// Authorize the request
-	config, err := google.JWTConfigFromJSON(
-		[]byte(credentialContent),
-		admin.AdminDirectoryUserReadonlyScope,
-		admin.AdminDirectoryOrgunitReadonlyScope,
-		admin.AdminDirectoryUserSecurityScope,
-		admin.AdminDirectoryGroupScope,
-		admin.AdminDirectoryGroupMemberScope,
-	)
+	// Credentials without a service account key sign the delegation JWT using the IAM Credentials API
+	credentialType, err := getCredentialType(credentialContent)
+	if err != nil {
+		return nil, err
+	}
+	switch credentialType {
+	case credentialTypeExternalAccount, credentialTypeImpersonatedServiceAccount:
+		return getKeylessDelegatedTokenSource(ctx, d, credentialContent, impersonateUser, scopes)
+	}
+
+	// Authorize the request with only the scopes required by the calling service
+	config, err := google.JWTConfigFromJSON([]byte(credentialContent), scopes...)
 	if err != nil {
 		return nil, err
 	}
 	config.Subject = impersonateUser
 
-	ts := config.TokenSource(ctx)
+	return config.TokenSource(ctx), nil
+}
 
-	// cache the token source
-	d.ConnectionManager.Cache.Set(cacheKey, ts)
+// Returns a TokenSource for the OAuth 2.0 credentials stored in the given file
+func getOAuthTokenSource(ctx context.Context, tokenPath string, scopes []string) (oauth2.TokenSource, error) {
+	path, err := expandPath(tokenPath)
+	if err != nil {
+		return nil, err
+	}
 
-	return ts, nil
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := google.CredentialsFromJSON(ctx, content, scopes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return creds.TokenSource, nil
+}
+
+// tokenSourceCacheKey returns the cache key for a token source with the given scopes
+func tokenSourceCacheKey(scopes []string) string {
+	return "googleworkspace.token_source." + scopesCacheKey(scopes)
+}
+
+// scopesCacheKey returns the part of a cache key identifying the given scopes.
+// The scopes are sorted, so that the same scope set always maps to the same key.
+func scopesCacheKey(scopes []string) string {
+	sorted := slices.Clone(scopes)
+	slices.Sort(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// subjectCacheKey scopes a cache key to the impersonated user, if any
+func subjectCacheKey(key string, subject string) string {
+	if subject == "" {
+		return key
+	}
+	return key + "." + subject
+}
+
+// getImpersonateUser returns the user to impersonate for the current query, as specified
+// using the optional `impersonate_user` qual. An empty string means the connection default.
+func getImpersonateUser(d *plugin.QueryData) string {
+	return d.EqualsQualString("impersonate_user")
 }
 
 func AdminService(ctx context.Context, d *plugin.QueryData) (*admin.Service, error) {
+	return adminServiceWithScopes(ctx, d, adminScopes...)
+}
+
+// adminServiceWithScopes returns an Admin SDK Directory service requesting only the given scopes.
+// Resources added after the initial set of scopes use their own scopes, so that domain-wide
+// delegation does not need to be authorized for them to keep querying the other tables.
+func adminServiceWithScopes(ctx context.Context, d *plugin.QueryData, scopes ...string) (*admin.Service, error) {
 	// Check if the service is already cached
-	serviceCacheKey := "googleworkspace.admin"
+	serviceCacheKey := "googleworkspace.admin." + scopesCacheKey(scopes)
 	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
 		return cachedData.(*admin.Service), nil
 	}
 
 	// Get session configuration
-	opts, err := getSessionConfig(ctx, d)
+	opts, err := getSessionConfig(ctx, d, "admin", "", scopes...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,232 @@
+package googleworkspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Credential types that do not hold a service account key, and delegate using the IAM Credentials API instead
+const (
+	credentialTypeExternalAccount            = "external_account"
+	credentialTypeImpersonatedServiceAccount = "impersonated_service_account"
+)
+
+// Matches the email of the service account in a service_account_impersonation_url, such as
+// https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/name@project.iam.gserviceaccount.com:generateAccessToken
+var serviceAccountImpersonationURLRegexp = regexp.MustCompile(`/serviceAccounts/([^/:]+):generateAccessToken$`)
+
+// keylessTokenURL is the token endpoint the signed JWT is exchanged at, as for service account keys.
+// It is a variable so that tests can exchange the JWT at a local stand-in endpoint.
+var keylessTokenURL = google.Endpoint.TokenURL
+
+// keylessCredentials holds the fields of external_account and impersonated_service_account
+// credentials used to delegate domain-wide authority
+type keylessCredentials struct {
+	Type                           string `json:"type"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+}
+
+// getCredentialType returns the type of the given JSON credentials
+func getCredentialType(content string) (string, error) {
+	var creds keylessCredentials
+	if err := json.Unmarshal([]byte(content), &creds); err != nil {
+		return "", fmt.Errorf("failed to parse credentials: %v", err)
+	}
+	return creds.Type, nil
+}
+
+// Returns a TokenSource that delegates domain-wide authority to the service account impersonated
+// by external_account or impersonated_service_account credentials, without a service account key.
+// The JWT that a key would sign is signed using the IAM Credentials API instead, so the service
+// account must be granted the Service Account Token Creator role on itself.
+func getKeylessDelegatedTokenSource(ctx context.Context, d *plugin.QueryData, content string, subject string, scopes []string) (oauth2.TokenSource, error) {
+	var creds keylessCredentials
+	if err := json.Unmarshal([]byte(content), &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %v", err)
+	}
+
+	matches := serviceAccountImpersonationURLRegexp.FindStringSubmatch(creds.ServiceAccountImpersonationURL)
+	if matches == nil {
+		return nil, fmt.Errorf("credentials of type %s must set service_account_impersonation_url to delegate domain-wide authority", creds.Type)
+	}
+	serviceAccount := matches[1]
+
+	// The token source is cached, and used by later queries, so it must not be cancelled with this one
+	ctx = context.WithoutCancel(ctx)
+
+	base, err := getBaseTransport(d)
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := iamCredentialsService(ctx, d, content)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &signJWTTokenSource{
+		ctx:            ctx,
+		client:         &http.Client{Transport: base},
+		serviceAccount: serviceAccount,
+		subject:        subject,
+		scopes:         scopes,
+		tokenURL:       keylessTokenURL,
+		signJWT: func(ctx context.Context, payload string) (string, error) {
+			name := "projects/-/serviceAccounts/" + serviceAccount
+			resp, err := service.Projects.ServiceAccounts.SignJwt(name, &iamcredentials.SignJwtRequest{Payload: payload}).Context(ctx).Do()
+			if err != nil {
+				return "", fmt.Errorf("failed to sign JWT as %s: %w", serviceAccount, err)
+			}
+			return resp.SignedJwt, nil
+		},
+	}
+
+	return oauth2.ReuseTokenSource(nil, ts), nil
+}
+
+// iamCredentialsService returns the IAM Credentials service, authenticated using the given credentials
+func iamCredentialsService(ctx context.Context, d *plugin.QueryData, content string) (*iamcredentials.Service, error) {
+	// have we already created and cached the service?
+	serviceCacheKey := "googleworkspace.iamcredentials"
+	if cachedData, ok := d.ConnectionManager.Cache.Get(serviceCacheKey); ok {
+		return cachedData.(*iamcredentials.Service), nil
+	}
+
+	base, err := getBaseTransport(d)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := google.CredentialsFromJSON(ctx, []byte(content), iamcredentials.CloudPlatformScope)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []option.ClientOption{
+		option.WithHTTPClient(&http.Client{Transport: &oauth2.Transport{Source: creds.TokenSource, Base: base}}),
+	}
+
+	// Send requests to the endpoint configured for the service, if any
+	endpoint, err := getEndpointOverride(d, "iamcredentials")
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	svc, err := iamcredentials.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// cache the service
+	d.ConnectionManager.Cache.Set(serviceCacheKey, svc)
+
+	return svc, nil
+}
+
+// signJWTTokenSource requests access tokens for a subject using a JWT signed by signJWT,
+// following the same JWT bearer flow as service account keys
+type signJWTTokenSource struct {
+	ctx            context.Context
+	client         *http.Client
+	serviceAccount string
+	subject        string
+	scopes         []string
+	tokenURL       string
+	signJWT        func(ctx context.Context, payload string) (string, error)
+}
+
+func (ts *signJWTTokenSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"iss":   ts.serviceAccount,
+		"sub":   ts.subject,
+		"scope": strings.Join(ts.scopes, " "),
+		"aud":   ts.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := ts.signJWT(ts.ctx, string(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ts.ctx, http.MethodPost, ts.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %w", err)
+	}
+
+	// Return the same error as the other token sources, so it can be inspected by callers
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		rerr := &oauth2.RetrieveError{Response: resp, Body: body}
+		var errorResponse struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			ErrorURI         string `json:"error_uri"`
+		}
+		if json.Unmarshal(body, &errorResponse) == nil {
+			rerr.ErrorCode = errorResponse.Error
+			rerr.ErrorDescription = errorResponse.ErrorDescription
+			rerr.ErrorURI = errorResponse.ErrorURI
+		}
+		return nil, rerr
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, fmt.Errorf("oauth2: cannot parse token response: %v", err)
+	}
+	if tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: server response missing access_token")
+	}
+
+	token := &oauth2.Token{
+		AccessToken: tokenResponse.AccessToken,
+		TokenType:   tokenResponse.TokenType,
+	}
+	if tokenResponse.ExpiresIn > 0 {
+		token.Expiry = now.Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,192 @@
+package googleworkspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testServiceAccount = "dwd@project.iam.gserviceaccount.com"
+	testSubject        = "admin@example.com"
+	testSignedJWT      = "header.payload.signature"
+)
+
+// newSignJWTTestServer returns a stand-in for the token endpoints and the IAM Credentials API, which
+// signs JWTs for testServiceAccount, and exchanges them for a token if tokenStatus is http.StatusOK
+func newSignJWTTestServer(t *testing.T, tokenStatus int) *httptest.Server {
+	mux := http.NewServeMux()
+
+	// Token endpoint of the source credentials
+	mux.HandleFunc("POST /source/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"source-token","token_type":"Bearer","expires_in":3600}`)
+	})
+
+	// Impersonation of the service account by the source credentials
+	mux.HandleFunc("POST /v1/projects/-/serviceAccounts/{account}", func(w http.ResponseWriter, r *http.Request) {
+		account := r.PathValue("account")
+		if r.Header.Get("Authorization") != "Bearer source-token" {
+			t.Errorf("%s: unexpected authorization %q", account, r.Header.Get("Authorization"))
+		}
+		if account != testServiceAccount+":generateAccessToken" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"accessToken":"iam-token","expireTime":"2100-01-01T00:00:00Z"}`)
+	})
+
+	// The signJwt call, made by the IAM Credentials client as the impersonated service account
+	mux.HandleFunc("POST /iam/v1/projects/-/serviceAccounts/{account}", func(w http.ResponseWriter, r *http.Request) {
+		if account := r.PathValue("account"); account != testServiceAccount+":signJwt" {
+			t.Errorf("unexpected signJwt call for %s", account)
+		}
+		if r.Header.Get("Authorization") != "Bearer iam-token" {
+			t.Errorf("signJwt: unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+
+		var req struct {
+			Payload string `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("signJwt: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var claims map[string]interface{}
+		if err := json.Unmarshal([]byte(req.Payload), &claims); err != nil {
+			t.Errorf("signJwt: invalid payload %q: %v", req.Payload, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for claim, want := range map[string]interface{}{
+			"iss":   testServiceAccount,
+			"sub":   testSubject,
+			"scope": strings.Join(adminScopes, " "),
+			"aud":   "http://" + r.Host + "/token",
+		} {
+			if claims[claim] != want {
+				t.Errorf("signJwt: claim %s = %v, want %v", claim, claims[claim], want)
+			}
+		}
+
+		fmt.Fprintf(w, `{"keyId":"key","signedJwt":%q}`, testSignedJWT)
+	})
+
+	// Token endpoint set by keylessTokenURL, which exchanges the signed JWT
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if grantType := r.FormValue("grant_type"); grantType != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("token: unexpected grant_type %q", grantType)
+		}
+		if assertion := r.FormValue("assertion"); assertion != testSignedJWT {
+			t.Errorf("token: unexpected assertion %q", assertion)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(tokenStatus)
+		if tokenStatus != http.StatusOK {
+			fmt.Fprint(w, `{"error":"unauthorized_client","error_description":"Client is unauthorized to retrieve access tokens using this method."}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"delegated-token","token_type":"Bearer","expires_in":3600}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newSignJWTTestQueryData returns the query data of a connection using impersonated_service_account
+// credentials, whose requests are all sent to the given server
+func newSignJWTTestQueryData(t *testing.T, server *httptest.Server) *plugin.QueryData {
+	tokenURL := keylessTokenURL
+	keylessTokenURL = server.URL + "/token"
+	t.Cleanup(func() { keylessTokenURL = tokenURL })
+
+	credentials, err := json.Marshal(map[string]interface{}{
+		"type":                              credentialTypeImpersonatedServiceAccount,
+		"service_account_impersonation_url": server.URL + "/v1/projects/-/serviceAccounts/" + testServiceAccount + ":generateAccessToken",
+		"source_credentials": map[string]string{
+			"type":          "authorized_user",
+			"client_id":     "client-id",
+			"client_secret": "client-secret",
+			"refresh_token": "refresh-token",
+			"token_uri":     server.URL + "/source/token",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := connection.NewConnectionCache("googleworkspace", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(credentials)
+	subject := testSubject
+	return &plugin.QueryData{
+		Connection: &plugin.Connection{
+			Name: "googleworkspace",
+			Config: googleworkspaceConfig{
+				Credentials:           &content,
+				ImpersonatedUserEmail: &subject,
+				EndpointOverrides:     map[string]string{"iamcredentials": server.URL + "/iam/"},
+			},
+		},
+		ConnectionManager: connection.NewManager(cache),
+	}
+}
+
+func TestKeylessDelegatedTokenSource(t *testing.T) {
+	server := newSignJWTTestServer(t, http.StatusOK)
+	d := newSignJWTTestQueryData(t, server)
+
+	ts, err := getTokenSource(context.Background(), d, "", adminScopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "delegated-token" {
+		t.Errorf("got access token %q, want %q", token.AccessToken, "delegated-token")
+	}
+	if token.Expiry.IsZero() {
+		t.Error("got token without expiry")
+	}
+}
+
+func TestKeylessDelegatedTokenSourceUnauthorized(t *testing.T) {
+	server := newSignJWTTestServer(t, http.StatusUnauthorized)
+	d := newSignJWTTestQueryData(t, server)
+
+	ts, err := getTokenSource(context.Background(), d, "", adminScopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Token()
+
+	// The connection check relies on the error code to report missing scopes
+	var rerr *oauth2.RetrieveError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("got error %v, want an *oauth2.RetrieveError", err)
+	}
+	if rerr.ErrorCode != "unauthorized_client" {
+		t.Errorf("got error code %q, want %q", rerr.ErrorCode, "unauthorized_client")
+	}
+	if status := connectionCheckStatus(err); status != connectionCheckScopeMissing {
+		t.Errorf("got connection check status %q, want %q", status, connectionCheckScopeMissing)
+	}
+}
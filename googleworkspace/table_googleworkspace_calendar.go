@@ -16,6 +16,7 @@ func tableGoogleWorkspaceCalendar(_ context.Context) *plugin.Table {
 		Description: "Metadata of the specified calendar.",
 		List: &plugin.ListConfig{
 			Hydrate:           listCalendars,
+			Tags:              map[string]string{"service": "calendar", "action": "list"},
 			KeyColumns:        plugin.SingleColumn("id"),
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
 		},
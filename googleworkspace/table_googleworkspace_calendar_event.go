@@ -241,6 +241,7 @@ func tableGoogleWorkspaceCalendarEvent(_ context.Context) *plugin.Table {
 		Description: "Events scheduled on the specified calendar.",
 		List: &plugin.ListConfig{
 			Hydrate:           listCalendarEvents,
+			Tags:              map[string]string{"service": "calendar", "action": "list"},
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
 			KeyColumns: []*plugin.KeyColumn{
 				{
@@ -261,6 +262,7 @@ func tableGoogleWorkspaceCalendarEvent(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.AllColumns([]string{"calendar_id", "id"}),
 			Hydrate:    getCalendarEvent,
+			Tags:       map[string]string{"service": "calendar", "action": "get"},
 		},
 		Columns: calendarEventColumns(),
 	}
@@ -314,6 +316,9 @@ func listCalendarEvents(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		}
 	}
 	if err := resp.Pages(ctx, func(page *calendar.Events) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, event := range page.Items {
 			d.StreamListItem(ctx, calendarEvent{*event, calendarID})
 
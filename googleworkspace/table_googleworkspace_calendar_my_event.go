@@ -17,6 +17,7 @@ func tableGoogleWorkspaceCalendarMyEvent(_ context.Context) *plugin.Table {
 		Description: "Events scheduled on the specified calendar.",
 		List: &plugin.ListConfig{
 			Hydrate:           listCalendarMyEvents,
+			Tags:              map[string]string{"service": "calendar", "action": "list"},
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
 			KeyColumns: []*plugin.KeyColumn{
 				{
@@ -28,9 +29,13 @@ func tableGoogleWorkspaceCalendarMyEvent(_ context.Context) *plugin.Table {
 					Require:   plugin.Optional,
 					Operators: []string{">", ">=", "=", "<", "<="},
 				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
 			},
 		},
-		Columns: calendarEventColumns(),
+		Columns: append(calendarEventColumns(), impersonateUserColumn()),
 	}
 }
 
@@ -81,6 +86,9 @@ func listCalendarMyEvents(ctx context.Context, d *plugin.QueryData, _ *plugin.Hy
 		}
 	}
 	if err := resp.Pages(ctx, func(page *calendar.Events) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, event := range page.Items {
 			d.StreamListItem(ctx, calendarEvent{*event, page.Summary})
 
@@ -0,0 +1,412 @@
+package googleworkspace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceChromeOSDevice(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_chromeos_device",
+		Description: "Retrieve the ChromeOS devices managed in the Google Workspace account.",
+		List: &plugin.ListConfig{
+			Hydrate: listChromeOSDevices,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "org_unit_path",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "serial_number",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "status",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "query",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("device_id"),
+			Hydrate:    getChromeOSDevice,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "device_id",
+				Description: "The unique ID of the ChromeOS device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DeviceId"),
+			},
+			{
+				Name:        "serial_number",
+				Description: "The serial number of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("SerialNumber"),
+			},
+			{
+				Name:        "status",
+				Description: "The status of the device (ACTIVE, DEPROVISIONED, DISABLED, INACTIVE, PRE_PROVISIONED, RETURN_ARRIVED, RETURN_REQUESTED, SHIPPED...).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "model",
+				Description: "The model of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "org_unit_path",
+				Description: "The full path to the organizational unit of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("OrgUnitPath"),
+			},
+			{
+				Name:        "org_unit_id",
+				Description: "The unique ID of the organizational unit of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("OrgUnitId"),
+			},
+			{
+				Name:        "annotated_user",
+				Description: "The user of the device, as annotated by the administrator.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("AnnotatedUser"),
+			},
+			{
+				Name:        "annotated_location",
+				Description: "The location of the device, as annotated by the administrator.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("AnnotatedLocation"),
+			},
+			{
+				Name:        "annotated_asset_id",
+				Description: "The asset identifier of the device, as annotated by the administrator.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("AnnotatedAssetId"),
+			},
+			{
+				Name:        "notes",
+				Description: "The notes added to the device by the administrator.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "os_version",
+				Description: "The version of ChromeOS of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("OsVersion"),
+			},
+			{
+				Name:        "platform_version",
+				Description: "The platform version of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PlatformVersion"),
+			},
+			{
+				Name:        "firmware_version",
+				Description: "The firmware version of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("FirmwareVersion"),
+			},
+			{
+				Name:        "boot_mode",
+				Description: "The boot mode of the device (Verified, Dev).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("BootMode"),
+			},
+			{
+				Name:        "os_update_status",
+				Description: "The status of the ChromeOS updates of the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("OsUpdateStatus"),
+			},
+			{
+				Name:        "auto_update_expiration",
+				Description: "The time the device stops receiving automatic ChromeOS updates and security fixes.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("AutoUpdateExpiration").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "days_until_aue",
+				Description: "The number of days until the auto update expiration of the device, which is negative once it has expired.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("AutoUpdateExpiration").NullIfZero().Transform(chromeOSDeviceDaysUntil),
+			},
+			{
+				Name:        "support_end_date",
+				Description: "The end date of the support of the device, after which it is no longer supported by Google.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("SupportEndDate").NullIfZero(),
+			},
+			{
+				Name:        "last_sync",
+				Description: "The time the device was last synchronized with the policy settings in the Admin console.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastSync").NullIfZero(),
+			},
+			{
+				Name:        "first_enrollment_time",
+				Description: "The time the device was first enrolled.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("FirstEnrollmentTime").NullIfZero(),
+			},
+			{
+				Name:        "last_enrollment_time",
+				Description: "The time the device was last enrolled.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastEnrollmentTime").NullIfZero(),
+			},
+			{
+				Name:        "recent_users",
+				Description: "The users who recently used the device, most recent first, with their email and type.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("RecentUsers"),
+			},
+			{
+				Name:        "active_time_ranges",
+				Description: "The time the device was in use on each recent day.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("ActiveTimeRanges"),
+			},
+			{
+				Name:        "disk_volume_reports",
+				Description: "The reports of the disk volumes of the device, with the free and total storage of each volume.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("DiskVolumeReports"),
+			},
+			{
+				Name:        "system_ram_total",
+				Description: "The total RAM of the device, in bytes.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("SystemRamTotal"),
+			},
+			{
+				Name:        "cpu_info",
+				Description: "Information about the CPUs of the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("CpuInfo"),
+			},
+			{
+				Name:        "tpm_version_info",
+				Description: "Information about the Trusted Platform Module (TPM) of the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("TpmVersionInfo"),
+			},
+			{
+				Name:        "last_known_network",
+				Description: "The IP addresses of the device on the last network it was connected to.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("LastKnownNetwork"),
+			},
+			{
+				Name:        "mac_address",
+				Description: "The wireless MAC address of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("MacAddress"),
+			},
+			{
+				Name:        "ethernet_mac_address",
+				Description: "The Ethernet MAC address of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("EthernetMacAddress"),
+			},
+			{
+				Name:        "meid",
+				Description: "The MEID or IMEI of the mobile card of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "manufacture_date",
+				Description: "The date the device was manufactured.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ManufactureDate"),
+			},
+			{
+				Name:        "order_number",
+				Description: "The order number of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("OrderNumber"),
+			},
+			{
+				Name:        "device_license_type",
+				Description: "The type of license of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DeviceLicenseType"),
+			},
+			{
+				Name:        "will_auto_renew",
+				Description: "Indicates if the support of the device is renewed automatically at its end date.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("WillAutoRenew"),
+			},
+			{
+				Name:        "deprovision_reason",
+				Description: "The reason the device was deprovisioned, if it was.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DeprovisionReason"),
+			},
+			{
+				Name:        "last_deprovision_timestamp",
+				Description: "The time the device was last deprovisioned.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastDeprovisionTimestamp").NullIfZero(),
+			},
+			{
+				Name:        "query",
+				Description: "A search query to filter ChromeOS devices with, using the Admin SDK search syntax, e.g. recent_user:alice@example.com.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("query"),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listChromeOSDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDeviceChromeosReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults := int64(200)
+	if d.QueryContext.Limit != nil {
+		if *d.QueryContext.Limit < maxResults {
+			maxResults = *d.QueryContext.Limit
+		}
+	}
+
+	req := service.Chromeosdevices.List(getCustomerID(d)).Projection("FULL").MaxResults(maxResults)
+
+	// List only the devices directly in the organizational unit, not in its children
+	if orgUnitPath := d.EqualsQualString("org_unit_path"); orgUnitPath != "" {
+		req = req.OrgUnitPath(orgUnitPath)
+	}
+	if query := buildChromeOSDevicesQuery(d); query != "" {
+		req = req.Query(query)
+	}
+
+	for {
+		resp, err := req.Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		for _, device := range resp.Chromeosdevices {
+			d.StreamListItem(ctx, device)
+
+			// Check if we should continue processing
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken(resp.NextPageToken)
+	}
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getChromeOSDevice(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	deviceId := d.EqualsQualString("device_id")
+
+	if deviceId == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDeviceChromeosReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := service.Chromeosdevices.Get(getCustomerID(d), deviceId).Projection("FULL").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// chromeOSDeviceStatusTerms maps the statuses of devices to the search terms matching them, which
+// differ from the statuses. Statuses without a search term are left for Postgres to filter on.
+var chromeOSDeviceStatusTerms = map[string]string{
+	"ACTIVE":        "status:provisioned",
+	"DEPROVISIONED": "status:deprovisioned",
+	"DISABLED":      "status:disabled",
+}
+
+// buildChromeOSDevicesQuery composes the quals of the table into a single Admin SDK search query.
+// Values that would need quoting are left for Postgres to filter on.
+func buildChromeOSDevicesQuery(d *plugin.QueryData) string {
+	var terms []string
+
+	if term, ok := chromeOSDeviceStatusTerms[d.EqualsQualString("status")]; ok {
+		terms = append(terms, term)
+	}
+
+	for _, filter := range []struct{ column, field string }{
+		{"serial_number", "id"},
+	} {
+		value := d.EqualsQualString(filter.column)
+		if value == "" || strings.ContainsAny(value, " \t\"'") {
+			continue
+		}
+		terms = append(terms, fmt.Sprintf("%s:%s", filter.field, value))
+	}
+
+	// The raw query is combined with the other filters, which are all ANDed together
+	if query := d.EqualsQualString("query"); query != "" {
+		terms = append(terms, query)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+//// TRANSFORM FUNCTIONS
+
+// chromeOSDeviceDaysUntil returns the number of whole days from now until the given time in milliseconds,
+// rounded down so that a time less than a day ago is -1 day away
+func chromeOSDeviceDaysUntil(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	if d.Value == nil {
+		return nil, nil
+	}
+
+	ms, err := types.ToInt64(d.Value)
+	if err != nil {
+		return nil, err
+	}
+	return int64(math.Floor(time.Until(time.UnixMilli(ms)).Hours() / 24)), nil
+}
@@ -0,0 +1,90 @@
+package googleworkspace
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestBuildChromeOSDevicesQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		quals []*quals.Qual
+		want  string
+	}{
+		{
+			name: "no quals",
+			want: "",
+		},
+		{
+			name:  "serial number",
+			quals: []*quals.Qual{stringQual("serial_number", "=", "5CD12345")},
+			want:  "id:5CD12345",
+		},
+		{
+			name:  "status is mapped to its search term",
+			quals: []*quals.Qual{stringQual("status", "=", "ACTIVE")},
+			want:  "status:provisioned",
+		},
+		{
+			name:  "status without a search term is skipped",
+			quals: []*quals.Qual{stringQual("status", "=", "SHIPPED")},
+			want:  "",
+		},
+		{
+			name:  "values that would need quoting are skipped",
+			quals: []*quals.Qual{stringQual("serial_number", "=", "5CD 12345")},
+			want:  "",
+		},
+		{
+			name: "quals are combined with the raw query",
+			quals: []*quals.Qual{
+				stringQual("query", "=", "user:jane"),
+				stringQual("status", "=", "DEPROVISIONED"),
+				stringQual("serial_number", "=", "5CD12345"),
+			},
+			want: "status:deprovisioned id:5CD12345 user:jane",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildChromeOSDevicesQuery(newQualsTestQueryData(tt.quals...)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChromeOSDeviceDaysUntil(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"no time", nil, nil},
+		{"in a few days", time.Now().Add(3*day + time.Hour).UnixMilli(), int64(3)},
+		{"in less than a day", time.Now().Add(time.Hour).UnixMilli(), int64(0)},
+		{"less than a day ago", time.Now().Add(-time.Hour).UnixMilli(), int64(-1)},
+		{"a few days ago", time.Now().Add(-3*day - time.Hour).UnixMilli(), int64(-4)},
+		{"time as a string", strconv.FormatInt(time.Now().Add(3*day+time.Hour).UnixMilli(), 10), int64(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chromeOSDeviceDaysUntil(context.Background(), &transform.TransformData{Value: tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,448 @@
+package googleworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	oauth2api "google.golang.org/api/oauth2/v2"
+	"google.golang.org/api/option"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// Statuses reported by the connection check
+const (
+	connectionCheckOK               = "ok"
+	connectionCheckSkipped          = "skipped"
+	connectionCheckAuthFailed       = "auth_failed"
+	connectionCheckAPIDisabled      = "api_disabled"
+	connectionCheckScopeMissing     = "scope_missing"
+	connectionCheckPermissionDenied = "permission_denied"
+	connectionCheckError            = "error"
+)
+
+// errConnectionCheckSkipped is returned by a probe when the API could not be called, e.g. for lack of a resource to get
+var errConnectionCheckSkipped = errors.New("probe skipped")
+
+// connectionCheckService describes how to check the connection to one of the services built in service.go
+type connectionCheckService struct {
+	name string
+	// Display name and service name of the API, as shown in the Google Cloud console
+	api     string
+	apiHost string
+	scopes  []string
+	// Whether the service can impersonate the user given by the impersonate_user qual
+	impersonates bool
+	// The Admin console privileges needed to call the probe, for Admin SDK services
+	adminPrivileges string
+	// A cheap call, used to check the API is reachable with the granted scopes
+	probe func(ctx context.Context, d *plugin.QueryData) error
+}
+
+// adminScopeCheckService describes how to check the connection to the Admin SDK using a scope
+// that is only requested by the tables that need it
+func adminScopeCheckService(name string, scope string, adminPrivileges string, probe func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error) connectionCheckService {
+	return connectionCheckService{
+		name:            name,
+		api:             "Admin SDK API",
+		apiHost:         "admin.googleapis.com",
+		scopes:          []string{scope},
+		adminPrivileges: adminPrivileges,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := adminServiceWithScopes(ctx, d, scope)
+			if err != nil {
+				return err
+			}
+			return probe(ctx, d, service)
+		},
+	}
+}
+
+var connectionCheckServices = []connectionCheckService{
+	{
+		name:            "admin",
+		api:             "Admin SDK API",
+		apiHost:         "admin.googleapis.com",
+		scopes:          adminScopes,
+		adminPrivileges: "users, groups and organizational units",
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := AdminService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.Users.List().Customer(getCustomerID(d)).MaxResults(1).Fields("users(id)").Context(ctx).Do()
+			return err
+		},
+	},
+	adminScopeCheckService("admin_chromeos_device", admin.AdminDirectoryDeviceChromeosReadonlyScope, "ChromeOS devices", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Chromeosdevices.List(getCustomerID(d)).MaxResults(1).Fields("chromeosdevices(deviceId)").Context(ctx).Do()
+		return err
+	}),
+	adminScopeCheckService("admin_customer", admin.AdminDirectoryCustomerReadonlyScope, "the customer account", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Customers.Get(getCustomerID(d)).Fields("id").Context(ctx).Do()
+		return err
+	}),
+	adminScopeCheckService("admin_domain", admin.AdminDirectoryDomainReadonlyScope, "domains", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Domains.List(getCustomerID(d)).Fields("domains(domainName)").Context(ctx).Do()
+		return err
+	}),
+	adminScopeCheckService("admin_mobile_device", admin.AdminDirectoryDeviceMobileReadonlyScope, "mobile devices", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Mobiledevices.List(getCustomerID(d)).MaxResults(1).Fields("mobiledevices(resourceId)").Context(ctx).Do()
+		return err
+	}),
+	adminScopeCheckService("admin_rolemanagement", admin.AdminDirectoryRolemanagementReadonlyScope, "admin roles", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Roles.List(getCustomerID(d)).MaxResults(1).Fields("items(roleId)").Context(ctx).Do()
+		return err
+	}),
+	adminScopeCheckService("admin_userschema", admin.AdminDirectoryUserschemaReadonlyScope, "custom user schemas", func(ctx context.Context, d *plugin.QueryData, service *admin.Service) error {
+		_, err := service.Schemas.List(getCustomerID(d)).Fields("schemas(schemaId)").Context(ctx).Do()
+		return err
+	}),
+	{
+		name:         "calendar",
+		api:          "Google Calendar API",
+		apiHost:      "calendar-json.googleapis.com",
+		scopes:       calendarScopes,
+		impersonates: true,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := CalendarService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.CalendarList.List().MaxResults(1).Fields("items(id)").Context(ctx).Do()
+			return err
+		},
+	},
+	{
+		name:         "drive",
+		api:          "Google Drive API",
+		apiHost:      "drive.googleapis.com",
+		scopes:       driveScopes,
+		impersonates: true,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := DriveService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.About.Get().Fields("user(emailAddress)").Context(ctx).Do()
+			return err
+		},
+	},
+	{
+		name:         "gmail",
+		api:          "Gmail API",
+		apiHost:      "gmail.googleapis.com",
+		scopes:       gmailScopes,
+		impersonates: true,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := GmailService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.Users.GetProfile("me").Fields("emailAddress").Context(ctx).Do()
+			return err
+		},
+	},
+	{
+		name:    "groupssettings",
+		api:     "Groups Settings API",
+		apiHost: "groupssettings.googleapis.com",
+		scopes:  groupsSettingsScopes,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			directoryService, err := AdminService(ctx, d)
+			if err != nil {
+				return err
+			}
+			groups, err := directoryService.Groups.List().Customer(getCustomerID(d)).MaxResults(1).Fields("groups(email)").Context(ctx).Do()
+			if err != nil {
+				return err
+			}
+			// The API can only be called for an existing group
+			if len(groups.Groups) == 0 {
+				return fmt.Errorf("%w: the customer has no group to get the settings of", errConnectionCheckSkipped)
+			}
+
+			service, err := GroupsSettingsService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.Groups.Get(groups.Groups[0].Email).Fields("email").Context(ctx).Do()
+			return err
+		},
+	},
+	{
+		name:         "people",
+		api:          "People API",
+		apiHost:      "people.googleapis.com",
+		scopes:       peopleScopes,
+		impersonates: true,
+		probe: func(ctx context.Context, d *plugin.QueryData) error {
+			service, err := PeopleService(ctx, d)
+			if err != nil {
+				return err
+			}
+			_, err = service.ContactGroups.List().PageSize(1).Fields("contactGroups(resourceName)").Context(ctx).Do()
+			return err
+		},
+	},
+}
+
+type connectionCheck struct {
+	Service         string
+	AuthMode        string
+	Subject         string
+	RequestedScopes []string
+	GrantedScopes   []string
+	MissingScopes   []string
+	Status          string
+	Error           string
+	Remediation     string
+}
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceConnectionCheck(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_connection_check",
+		Description: "Checks the authentication, granted scopes and reachability of each Google Workspace API used by the connection.",
+		List: &plugin.ListConfig{
+			Hydrate: listConnectionChecks,
+			// Most probes call the Admin SDK, so they all count against its limiter
+			Tags: map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "service",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "service",
+				Description: "The service checked, one of admin, calendar, drive, gmail, groupssettings or people. The Admin SDK scopes only required by some tables are checked separately, as admin_chromeos_device, admin_customer, admin_domain, admin_mobile_device, admin_rolemanagement and admin_userschema.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The result of the check, one of ok, skipped, auth_failed, api_disabled, scope_missing, permission_denied or error. Checks are skipped when the API could not be called, in which case whether it is reachable is unknown.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "auth_mode",
+				Description: "The authentication mode used by the connection, one of domain_wide_delegation, oauth_token or application_default_credentials.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "subject",
+				Description: "The user the service acts as, if known.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "requested_scopes",
+				Description: "The OAuth 2.0 scopes requested by the service.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "granted_scopes",
+				Description: "The OAuth 2.0 scopes granted to the access token of the service.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "missing_scopes",
+				Description: "The requested OAuth 2.0 scopes that were not granted.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "error",
+				Description: "The error returned while authenticating or calling the API, if any.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "remediation",
+				Description: "A hint on how to fix the failure, if any.",
+				Type:        proto.ColumnType_STRING,
+			},
+			impersonateUserColumn(),
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listConnectionChecks(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	base, err := getBaseTransport(d)
+	if err != nil {
+		plugin.Logger(ctx).Error("googleworkspace_connection_check.listConnectionChecks", "transport_error", err)
+		return nil, err
+	}
+
+	// The token info endpoint does not require authentication
+	tokenInfoService, err := oauth2api.NewService(ctx, option.WithHTTPClient(&http.Client{Transport: base}))
+	if err != nil {
+		plugin.Logger(ctx).Error("googleworkspace_connection_check.listConnectionChecks", "service_creation_error", err)
+		return nil, err
+	}
+
+	serviceName := d.EqualsQualString("service")
+	for _, service := range connectionCheckServices {
+		if serviceName != "" && serviceName != service.name {
+			continue
+		}
+
+		// Apply the list rate limiters to each probe
+		d.WaitForListRateLimit(ctx)
+
+		d.StreamListItem(ctx, checkConnection(ctx, d, tokenInfoService, service))
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkConnection authenticates the given service, fetches the scopes granted to its access token,
+// and makes a probe call to the API
+func checkConnection(ctx context.Context, d *plugin.QueryData, tokenInfoService *oauth2api.Service, service connectionCheckService) connectionCheck {
+	var subject string
+	if service.impersonates {
+		subject = getImpersonateUser(d)
+	}
+
+	check := connectionCheck{
+		Service:         service.name,
+		AuthMode:        getAuthMode(d),
+		RequestedScopes: service.scopes,
+	}
+	if check.AuthMode == authModeDomainWideDelegation {
+		check.Subject = getDelegatedSubject(d, subject)
+	}
+
+	ts, err := getTokenSource(ctx, d, subject, service.scopes)
+	if err != nil {
+		return check.failed(service, err)
+	}
+	token, err := ts.Token()
+	if err != nil {
+		return check.failed(service, err)
+	}
+
+	// The granted scopes are informational only, so do not fail the check if they cannot be fetched
+	info, err := tokenInfoService.Tokeninfo().AccessToken(token.AccessToken).Context(ctx).Do()
+	if err != nil {
+		plugin.Logger(ctx).Warn("googleworkspace_connection_check.checkConnection", "service", service.name, "token_info_error", err)
+	} else {
+		check.GrantedScopes = strings.Fields(info.Scope)
+		for _, scope := range service.scopes {
+			if !slices.Contains(check.GrantedScopes, scope) {
+				check.MissingScopes = append(check.MissingScopes, scope)
+			}
+		}
+		if check.Subject == "" {
+			check.Subject = info.Email
+		}
+	}
+
+	if err := service.probe(ctx, d); err != nil {
+		if errors.Is(err, errConnectionCheckSkipped) {
+			check.Error = err.Error()
+			check.Status = connectionCheckSkipped
+			return check
+		}
+		return check.failed(service, err)
+	}
+	check.Status = connectionCheckOK
+
+	return check
+}
+
+// failed records the given error in the check, along with its status and a remediation hint
+func (check connectionCheck) failed(service connectionCheckService, err error) connectionCheck {
+	check.Error = err.Error()
+	check.Status = connectionCheckStatus(err)
+
+	switch check.Status {
+	case connectionCheckAuthFailed:
+		switch check.AuthMode {
+		case authModeDomainWideDelegation:
+			check.Remediation = "Check that the service account key is valid, and that the impersonated user is an active user of the domain."
+		case authModeOAuthToken:
+			check.Remediation = "Generate a new token using `gcloud auth application-default login`, and set token_path to the credentials file it saved."
+		default:
+			check.Remediation = "Configure credentials and impersonated_user_email, or token_path, or set the GOOGLE_APPLICATION_CREDENTIALS environment variable."
+		}
+	case connectionCheckAPIDisabled:
+		check.Remediation = fmt.Sprintf("Enable the %s in the Google Cloud project of the credentials: https://console.cloud.google.com/apis/library/%s", service.api, service.apiHost)
+	case connectionCheckScopeMissing:
+		if check.AuthMode == authModeDomainWideDelegation {
+			check.Remediation = fmt.Sprintf("Authorize the client ID of the service account for the scopes %s in the Admin console, under Security > Access and data control > API controls > Domain-wide delegation.", strings.Join(service.scopes, ","))
+		} else {
+			check.Remediation = fmt.Sprintf("Authenticate again, requesting the scopes %s.", strings.Join(service.scopes, ","))
+		}
+	case connectionCheckPermissionDenied:
+		if service.adminPrivileges != "" {
+			check.Remediation = fmt.Sprintf("Impersonate a user with an administrator role that can read %s.", service.adminPrivileges)
+		} else {
+			check.Remediation = fmt.Sprintf("Check that the user has a Google Workspace license, and that the %s service is turned on for them in the Admin console.", service.name)
+		}
+	}
+
+	return check
+}
+
+// connectionCheckStatus returns the status of a check that failed with the given error
+func connectionCheckStatus(err error) string {
+	// Errors returned by the token endpoint
+	var rerr *oauth2.RetrieveError
+	if errors.As(err, &rerr) {
+		// Returned when domain-wide delegation is not authorized for the requested scopes
+		if rerr.ErrorCode == "unauthorized_client" {
+			return connectionCheckScopeMissing
+		}
+		return connectionCheckAuthFailed
+	}
+
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		// Errors building the service, such as invalid or missing credentials
+		return connectionCheckAuthFailed
+	}
+
+	switch gerr.Code {
+	case http.StatusUnauthorized:
+		return connectionCheckAuthFailed
+	case http.StatusForbidden:
+		for _, item := range gerr.Errors {
+			switch item.Reason {
+			case "accessNotConfigured":
+				return connectionCheckAPIDisabled
+			case "insufficientPermissions":
+				return connectionCheckScopeMissing
+			}
+		}
+		// Errors that only report the reason in their details
+		if strings.Contains(gerr.Body, "SERVICE_DISABLED") {
+			return connectionCheckAPIDisabled
+		}
+		if strings.Contains(gerr.Body, "ACCESS_TOKEN_SCOPE_INSUFFICIENT") {
+			return connectionCheckScopeMissing
+		}
+		return connectionCheckPermissionDenied
+	}
+
+	return connectionCheckError
+}
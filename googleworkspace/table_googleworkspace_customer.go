@@ -0,0 +1,129 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceCustomer(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_customer",
+		Description: "Retrieve the Google Workspace account of the customer.",
+		List: &plugin.ListConfig{
+			Hydrate: listCustomer,
+			Tags:    map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "customer_id",
+				Description: "The unique ID of the customer.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Id"),
+			},
+			{
+				Name:        "customer_domain",
+				Description: "The primary domain of the customer.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("CustomerDomain"),
+			},
+			{
+				Name:        "customer_creation_time",
+				Description: "The time the customer was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("CustomerCreationTime").NullIfZero(),
+			},
+			{
+				Name:        "alternate_email",
+				Description: "The secondary email address of the customer, which must not be in the customer's domain.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("AlternateEmail"),
+			},
+			{
+				Name:        "language",
+				Description: "The language of the customer, as an ISO 639-1 code.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "phone_number",
+				Description: "The phone number of the customer, in E.164 format.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PhoneNumber"),
+			},
+			{
+				Name:        "postal_address",
+				Description: "The postal address of the customer.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("PostalAddress"),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listCustomer(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	customer, err := getCustomer(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, customer)
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+// getResolvedCustomerID returns the unique ID of the customer, resolving the `my_customer` alias
+// so that it can be joined with the customer_id of the other Directory tables
+func getResolvedCustomerID(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	customerId := getCustomerID(d)
+	if customerId != "my_customer" {
+		return customerId, nil
+	}
+
+	// have we already resolved and cached the customer ID?
+	cacheKey := "googleworkspace.customer_id"
+	if cachedData, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
+		return cachedData.(string), nil
+	}
+
+	customer, err := getCustomer(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// cache the customer ID
+	d.ConnectionManager.Cache.Set(cacheKey, customer.Id)
+
+	return customer.Id, nil
+}
+
+// getCustomer gets the customer configured for the connection
+func getCustomer(ctx context.Context, d *plugin.QueryData) (*admin.Customer, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryCustomerReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("id,customerDomain,customerCreationTime,alternateEmail,language,phoneNumber,postalAddress,etag,kind")
+
+	return service.Customers.Get(getCustomerID(d)).Fields(fields).Context(ctx).Do()
+}
@@ -0,0 +1,188 @@
+package googleworkspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// Deleted users can be restored for 20 days after their deletion
+const deletedUserRestoreWindow = 20 * 24 * time.Hour
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceDirectoryDeletedUser(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_directory_deleted_user",
+		Description: "Retrieve users deleted from the Google Workspace directory in the last 20 days, which can still be restored.",
+		List: &plugin.ListConfig{
+			Hydrate: listDirectoryDeletedUsers,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "id",
+				Description: "The unique ID for the user.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "primary_email",
+				Description: "The user's primary email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail"),
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the user's primary email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail").Transform(emailDomain),
+			},
+			{
+				Name:        "full_name",
+				Description: "The user's full name.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Name.FullName"),
+			},
+			{
+				Name:        "deletion_time",
+				Description: "The time the user was deleted.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("DeletionTime").NullIfZero(),
+			},
+			{
+				Name:        "restorable_until",
+				Description: "The time until which the user can be restored, 20 days after their deletion.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("DeletionTime").Transform(deletedUserRestorableUntil),
+			},
+			{
+				Name:        "org_unit_path",
+				Description: "The full path to the organizational unit the user was in.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("OrgUnitPath"),
+			},
+			{
+				Name:        "is_admin",
+				Description: "Indicates if the user was an administrator.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsAdmin"),
+			},
+			{
+				Name:        "is_suspended",
+				Description: "Indicates if the user was suspended.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Suspended"),
+			},
+			{
+				Name:        "archived",
+				Description: "Indicates if the user was archived.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "last_login_time",
+				Description: "The last time the user logged in.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastLoginTime").NullIfZero(),
+			},
+			{
+				Name:        "creation_time",
+				Description: "The time the user was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("CreationTime").NullIfZero(),
+			},
+			{
+				Name:        "customer_id",
+				Description: "The customer ID.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("CustomerId"),
+			},
+			{
+				Name:        "aliases",
+				Description: "The user's email aliases.",
+				Type:        proto.ColumnType_JSON,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listDirectoryDeletedUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("nextPageToken,users(id,primaryEmail,name/fullName,deletionTime,orgUnitPath,isAdmin,suspended,archived,lastLoginTime,creationTime,customerId,aliases)")
+
+	maxResults := int64(500)
+	if d.QueryContext.Limit != nil {
+		if *d.QueryContext.Limit < maxResults {
+			maxResults = *d.QueryContext.Limit
+		}
+	}
+
+	for _, domain := range getDomains(d) {
+		resp := service.Users.List().ShowDeleted("true").Fields(fields).MaxResults(maxResults)
+
+		// List users of the given domain, or of all domains of the customer
+		if domain != "" {
+			resp = resp.Domain(domain)
+		} else {
+			resp = resp.Customer(getCustomerID(d))
+		}
+
+		err = resp.Pages(ctx, func(page *admin.Users) error {
+			// Apply the list rate limiters to each page, before the next one is requested
+			d.WaitForListRateLimit(ctx)
+
+			for _, user := range page.Users {
+				d.StreamListItem(ctx, user)
+
+				if d.RowsRemaining(ctx) == 0 {
+					page.NextPageToken = ""
+					return nil
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			plugin.Logger(ctx).Error("googleworkspace_directory_deleted_user.listDirectoryDeletedUsers", "api_error", err)
+			return nil, err
+		}
+
+		if d.RowsRemaining(ctx) == 0 {
+			break
+		}
+	}
+
+	return nil, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// deletedUserRestorableUntil returns the time until which a user deleted at the given time can be restored
+func deletedUserRestorableUntil(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	deletionTime := types.SafeString(d.Value)
+	if deletionTime == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, deletionTime)
+	if err != nil {
+		return nil, err
+	}
+	return t.Add(deletedUserRestoreWindow), nil
+}
@@ -2,7 +2,14 @@ package googleworkspace
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/iancoleman/strcase"
+	"github.com/turbot/go-kit/types"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -10,6 +17,18 @@ import (
 	"google.golang.org/api/googleapi"
 )
 
+// Views of users, as returned to administrators, or to all users of the domain
+const (
+	directoryUsersAdminView        = "admin_view"
+	directoryUsersDomainPublicView = "domain_public"
+)
+
+// Cache key of the view to list users with, once listing them with the admin view has been forbidden
+const directoryUsersViewTypeCacheKey = "googleworkspace.directory_users.view_type"
+
+// Matches the schema name of the custom fields filtered on in an Admin SDK search query, e.g. EmploymentData.EmployeeType='Contractor'
+var directoryUsersSchemaQueryRegexp = regexp.MustCompile(`(?:^|[\s(])([A-Za-z][A-Za-z0-9_]*)\.[A-Za-z][A-Za-z0-9_]*\s*[=:<>]`)
+
 //// TABLE DEFINITION
 
 func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
@@ -18,6 +37,61 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 		Description: "Retrieve information about users in the Google Workspace directory.",
 		List: &plugin.ListConfig{
 			Hydrate: listDirectoryUsers,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+				{
+					Name:      "is_admin",
+					Require:   plugin.Optional,
+					Operators: []string{"=", "<>"},
+				},
+				{
+					Name:      "is_suspended",
+					Require:   plugin.Optional,
+					Operators: []string{"=", "<>"},
+				},
+				{
+					Name:    "org_unit_path",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "given_name",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "family_name",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "query",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "view_type",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "id",
+					Require: plugin.AnyOf,
+				},
+				{
+					Name:    "primary_email",
+					Require: plugin.AnyOf,
+				},
+				{
+					Name:    "view_type",
+					Require: plugin.Optional,
+				},
+			},
+			Hydrate: getDirectoryUser,
+			Tags:    map[string]string{"service": "admin", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -31,6 +105,12 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_STRING,
 				Transform:   transform.FromField("PrimaryEmail"),
 			},
+			{
+				Name:        "domain",
+				Description: "The domain of the user's primary email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail").Transform(emailDomain),
+			},
 			{
 				Name:        "name",
 				Description: "The user's name details.",
@@ -107,6 +187,24 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_BOOL,
 				Transform:   transform.FromField("IpWhitelisted"),
 			},
+			{
+				Name:        "is_enrolled_in_2sv",
+				Description: "Indicates if the user is enrolled in 2-Step Verification.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsEnrolledIn2Sv"),
+			},
+			{
+				Name:        "is_enforced_in_2sv",
+				Description: "Indicates if 2-Step Verification is enforced for the user.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsEnforcedIn2Sv"),
+			},
+			{
+				Name:        "is_guest_user",
+				Description: "Indicates if the user is a guest user.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsGuestUser"),
+			},
 			{
 				Name:        "is_mailbox_setup",
 				Description: "Indicates if the user's mailbox is set up.",
@@ -119,6 +217,12 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_TIMESTAMP,
 				Transform:   transform.FromField("LastLoginTime").NullIfZero(),
 			},
+			{
+				Name:        "never_logged_in",
+				Description: "Indicates if the user has never logged in.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("LastLoginTime").Transform(neverLoggedIn),
+			},
 			{
 				Name:        "creation_time",
 				Description: "The time the user was created.",
@@ -150,14 +254,15 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "hash_function",
-				Description: "The hash function used for the password.",
+				Description: "Deprecated: the hash function of the password is no longer requested, so this column is always null.",
 				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromField("HashFunction"),
+				Transform:   transform.FromConstant(nil),
 			},
 			{
 				Name:        "password",
-				Description: "The user's password (write-only).",
+				Description: "Deprecated: passwords are write-only and are no longer requested, so this column is always null.",
 				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromConstant(nil),
 			},
 			{
 				Name:        "recovery_email",
@@ -268,14 +373,32 @@ func tableGoogleWorkspaceDirectoryUsers(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "custom_schemas",
-				Description: "Custom fields for the user.",
+				Description: "Custom fields for the user. If the query filters on custom fields, only the schemas filtered on are returned.",
 				Type:        proto.ColumnType_JSON,
 				Transform:   transform.FromField("CustomSchemas"),
 			},
+			{
+				Name:        "query",
+				Description: "A search query to filter users with, using the Admin SDK search syntax, e.g. isEnrolledIn2Sv=false. See https://developers.google.com/admin-sdk/directory/v1/guides/search-users.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("query"),
+			},
+			{
+				Name:        "view_type",
+				Description: "The view the user was retrieved with, either admin_view, or domain_public for the public profile visible to all users of the domain, in which case administrator-only columns are null. Defaults to admin_view, falling back to domain_public if the user querying is not an administrator.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ViewType"),
+			},
 		},
 	}
 }
 
+// DirectoryUser is a user, together with the view it has been retrieved with
+type DirectoryUser struct {
+	admin.User
+	ViewType string
+}
+
 //// LIST FUNCTION
 
 func listDirectoryUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
@@ -284,7 +407,36 @@ func listDirectoryUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		return nil, err
 	}
 
-	fields := googleapi.Field("users(id,primaryEmail,name,isAdmin,isDelegatedAdmin,suspended,suspensionReason,archived,agreedToTerms,changePasswordAtNextLogin,includeInGlobalAddressList,ipWhitelisted,isMailboxSetup,lastLoginTime,creationTime,deletionTime,orgUnitPath,customerId,etag,hashFunction,password,recoveryEmail,recoveryPhone,thumbnailPhotoEtag,thumbnailPhotoUrl,addresses,aliases,emails,externalIds,gender,ims,keywords,languages,locations,notes,organizations,phones,posixAccounts,relations,sshPublicKeys,websites,customSchemas)")
+	viewType, forced, err := getDirectoryUsersViewType(d)
+	if err != nil {
+		return nil, err
+	}
+
+	streamed, err := listDirectoryUsersInView(ctx, d, service, viewType)
+
+	// Users who are not administrators can only list the public profile of users, so fall back to it
+	if isNotAdminError(err) && !forced && !streamed && viewType == directoryUsersAdminView {
+		plugin.Logger(ctx).Warn("googleworkspace_directory_users.listDirectoryUsers", "admin_view_error", err, "fallback", directoryUsersDomainPublicView)
+		_, err = listDirectoryUsersInView(ctx, d, service, directoryUsersDomainPublicView)
+
+		// Only remember the fallback for the connection once it has succeeded
+		if err == nil {
+			d.ConnectionManager.Cache.Set(directoryUsersViewTypeCacheKey, directoryUsersDomainPublicView)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listDirectoryUsersInView streams the users matching the query using the given view, and returns
+// whether any user has been streamed
+func listDirectoryUsersInView(ctx context.Context, d *plugin.QueryData, service *admin.Service, viewType string) (bool, error) {
+	// Only request the fields, and custom schemas, of the selected columns
+	fields := googleapi.Field("nextPageToken,users(" + buildDirectoryUserRequestFields(d.QueryContext.Columns) + ")")
+	projection, customFieldMask := buildDirectoryUserProjection(d)
 
 	maxResults := int64(100)
 	if d.QueryContext.Limit != nil {
@@ -293,29 +445,265 @@ func listDirectoryUsers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 		}
 	}
 
-	resp := service.Users.List().Customer("my_customer").Fields(fields).MaxResults(maxResults)
+	query := buildDirectoryUsersQuery(d)
 
-	if d.EqualsQualString("primary_email") != "" {
-		resp = resp.Query("email:" + d.EqualsQualString("primary_email"))
+	// The public profile of users can only be listed per domain
+	domains := getDomains(d)
+	if viewType == directoryUsersDomainPublicView {
+		var err error
+		domains, err = getDirectoryUsersPublicDomains(d)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	if d.EqualsQualString("org_unit_path") != "" {
-		resp = resp.Query("orgUnitPath:" + d.EqualsQualString("org_unit_path"))
-	}
+	var streamed bool
+	for _, domain := range domains {
+		resp := service.Users.List().Fields(fields).MaxResults(maxResults).Projection(projection).ViewType(viewType)
+		if customFieldMask != "" {
+			resp = resp.CustomFieldMask(customFieldMask)
+		}
+
+		// List users of the given domain, or of all domains of the customer
+		if domain != "" {
+			resp = resp.Domain(domain)
+		} else {
+			resp = resp.Customer(getCustomerID(d))
+		}
 
-	err = resp.Pages(ctx, func(page *admin.Users) error {
-		for _, user := range page.Users {
-			d.StreamListItem(ctx, user)
+		if query != "" {
+			resp = resp.Query(query)
+		}
 
-			if d.RowsRemaining(ctx) == 0 {
-				return nil
+		err := resp.Pages(ctx, func(page *admin.Users) error {
+			// Apply the list rate limiters to each page, before the next one is requested
+			d.WaitForListRateLimit(ctx)
+
+			for _, user := range page.Users {
+				d.StreamListItem(ctx, &DirectoryUser{User: *user, ViewType: viewType})
+				streamed = true
+
+				if d.RowsRemaining(ctx) == 0 {
+					page.NextPageToken = ""
+					return nil
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			return streamed, err
+		}
+
+		if d.RowsRemaining(ctx) == 0 {
+			break
 		}
-		return nil
-	})
+	}
+
+	return streamed, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getDirectoryUser(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	// Users can be retrieved by their ID, or any of their email addresses
+	userKey := d.EqualsQualString("id")
+	if userKey == "" {
+		userKey = d.EqualsQualString("primary_email")
+	}
+
+	if userKey == "" {
+		return nil, nil
+	}
+
+	service, err := AdminService(ctx, d)
 	if err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	viewType, forced, err := getDirectoryUsersViewType(d)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field(buildDirectoryUserRequestFields(d.QueryContext.Columns))
+	projection, customFieldMask := buildDirectoryUserProjection(d)
+
+	getUser := func(viewType string) (*admin.User, error) {
+		call := service.Users.Get(userKey).Fields(fields).Projection(projection).ViewType(viewType)
+		if customFieldMask != "" {
+			call = call.CustomFieldMask(customFieldMask)
+		}
+		return call.Context(ctx).Do()
+	}
+
+	user, err := getUser(viewType)
+
+	// Users who are not administrators can only get the public profile of users, so fall back to it
+	if isNotAdminError(err) && !forced && viewType == directoryUsersAdminView {
+		plugin.Logger(ctx).Warn("googleworkspace_directory_users.getDirectoryUser", "admin_view_error", err, "fallback", directoryUsersDomainPublicView)
+		viewType = directoryUsersDomainPublicView
+		user, err = getUser(viewType)
+
+		// Only remember the fallback for the connection once it has succeeded
+		if err == nil {
+			d.ConnectionManager.Cache.Set(directoryUsersViewTypeCacheKey, directoryUsersDomainPublicView)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectoryUser{User: *user, ViewType: viewType}, nil
+}
+
+// getDirectoryUsersViewType returns the view to retrieve users with, and whether it has been set using
+// the view_type qual or config. The view_type qual takes precedence over the configured one. If neither
+// is set, the admin view is used, unless it has already been forbidden for the connection.
+func getDirectoryUsersViewType(d *plugin.QueryData) (string, bool, error) {
+	viewType := d.EqualsQualString("view_type")
+	if viewType == "" {
+		googleworkspaceConfig := GetConfig(d.Connection)
+		if googleworkspaceConfig.ViewType != nil {
+			viewType = *googleworkspaceConfig.ViewType
+		}
+	}
+
+	switch viewType {
+	case directoryUsersAdminView, directoryUsersDomainPublicView:
+		return viewType, true, nil
+	case "":
+		if cachedData, ok := d.ConnectionManager.Cache.Get(directoryUsersViewTypeCacheKey); ok {
+			return cachedData.(string), false, nil
+		}
+		return directoryUsersAdminView, false, nil
+	default:
+		return "", false, fmt.Errorf("invalid view_type %q: must be %s or %s", viewType, directoryUsersAdminView, directoryUsersDomainPublicView)
+	}
+}
+
+// getDirectoryUsersPublicDomains returns the domains to list the public profile of users for, since
+// it cannot be listed for all domains of the customer at once. Unless domains are given by the domain
+// qual or config, the domain of the user impersonated using domain-wide delegation is used.
+func getDirectoryUsersPublicDomains(d *plugin.QueryData) ([]string, error) {
+	domains := getDomains(d)
+	if !slices.Contains(domains, "") {
+		return domains, nil
+	}
+
+	if getAuthMode(d) == authModeDomainWideDelegation {
+		subject := getDelegatedSubject(d, "")
+		if i := strings.LastIndex(subject, "@"); i >= 0 {
+			return []string{subject[i+1:]}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("listing users with the %s view requires a domain: set domains in the connection config, or filter on the domain column", directoryUsersDomainPublicView)
+}
+
+// buildDirectoryUsersQuery composes the quals of the table into a single Admin SDK search query.
+// See https://developers.google.com/admin-sdk/directory/v1/guides/search-users
+func buildDirectoryUsersQuery(d *plugin.QueryData) string {
+	var terms []string
+
+	for _, filter := range []struct{ column, field string }{
+		{"is_admin", "isAdmin"},
+		{"is_suspended", "isSuspended"},
+	} {
+		if d.Quals[filter.column] == nil {
+			continue
+		}
+		for _, q := range d.Quals[filter.column].Quals {
+			value := q.Value.GetBoolValue()
+			if q.Operator == "<>" {
+				value = !value
+			}
+			terms = append(terms, fmt.Sprintf("%s=%t", filter.field, value))
+		}
+	}
+
+	for _, filter := range []struct{ column, field string }{
+		{"org_unit_path", "orgUnitPath"},
+		{"given_name", "givenName"},
+		{"family_name", "familyName"},
+	} {
+		if value := d.EqualsQualString(filter.column); value != "" {
+			terms = append(terms, fmt.Sprintf("%s=%s", filter.field, quoteSearchValue(value)))
+		}
+	}
+
+	// The raw query is combined with the other filters, which are all ANDed together
+	if query := d.EqualsQualString("query"); query != "" {
+		terms = append(terms, query)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// buildDirectoryUserRequestFields :: Return the fields of a user required by the columns passed in query context
+func buildDirectoryUserRequestFields(queryColumns []string) string {
+	// Since ID is unique, always add in the requested field
+	fields := []string{"id"}
+
+	for _, columnName := range queryColumns {
+		var field string
+		switch columnName {
+		// Optional columns
+		case "id", "query", "view_type", "hash_function", "password", "_ctx":
+			continue
+		case "domain":
+			field = "primaryEmail"
+		case "given_name", "family_name", "full_name":
+			field = "name"
+		case "is_suspended":
+			field = "suspended"
+		case "never_logged_in":
+			field = "lastLoginTime"
+		default:
+			field = strcase.ToLowerCamel(columnName)
+		}
+
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// buildDirectoryUserProjection returns the projection, and custom field mask, to request users with.
+// Custom schemas are only requested if the custom_schemas column is selected. If the query filters
+// on custom fields, only the schemas filtered on are requested.
+func buildDirectoryUserProjection(d *plugin.QueryData) (string, string) {
+	if !slices.Contains(d.QueryContext.Columns, "custom_schemas") {
+		return "basic", ""
+	}
+
+	var schemas []string
+	for _, match := range directoryUsersSchemaQueryRegexp.FindAllStringSubmatch(d.EqualsQualString("query"), -1) {
+		if !slices.Contains(schemas, match[1]) {
+			schemas = append(schemas, match[1])
+		}
+	}
+	if len(schemas) > 0 {
+		return "custom", strings.Join(schemas, ",")
+	}
+
+	return "full", ""
+}
+
+//// TRANSFORM FUNCTIONS
+
+// neverLoggedIn returns true if the last login time of a user is the Unix epoch, which the
+// Admin SDK reports for users who have never logged in
+func neverLoggedIn(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	lastLoginTime := types.SafeString(d.Value)
+	if lastLoginTime == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, lastLoginTime)
+	if err != nil {
+		return nil, err
+	}
+	return t.Unix() == 0, nil
 }
@@ -0,0 +1,128 @@
+package googleworkspace
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+// stringQual returns a qual comparing the given column to a string value
+func stringQual(column, operator, value string) *quals.Qual {
+	return &quals.Qual{Column: column, Operator: operator, Value: &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: value}}}
+}
+
+// boolQual returns a qual comparing the given column to a boolean value
+func boolQual(column, operator string, value bool) *quals.Qual {
+	return &quals.Qual{Column: column, Operator: operator, Value: &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: value}}}
+}
+
+// newQualsTestQueryData returns the query data of a query with the given quals
+func newQualsTestQueryData(qs ...*quals.Qual) *plugin.QueryData {
+	d := &plugin.QueryData{
+		Quals:       plugin.KeyColumnQualMap{},
+		EqualsQuals: plugin.KeyColumnEqualsQualMap{},
+	}
+	for _, q := range qs {
+		if d.Quals[q.Column] == nil {
+			d.Quals[q.Column] = &plugin.KeyColumnQuals{Name: q.Column}
+		}
+		d.Quals[q.Column].Quals = append(d.Quals[q.Column].Quals, q)
+		if q.Operator == "=" {
+			d.EqualsQuals[q.Column] = q.Value
+		}
+	}
+	return d
+}
+
+func TestBuildDirectoryUsersQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		quals []*quals.Qual
+		want  string
+	}{
+		{
+			name: "no quals",
+			want: "",
+		},
+		{
+			name:  "boolean equals",
+			quals: []*quals.Qual{boolQual("is_admin", "=", true)},
+			want:  "isAdmin=true",
+		},
+		{
+			name:  "boolean not equals",
+			quals: []*quals.Qual{boolQual("is_suspended", "<>", true)},
+			want:  "isSuspended=false",
+		},
+		{
+			name:  "string values are quoted",
+			quals: []*quals.Qual{stringQual("org_unit_path", "=", "/Sales/EMEA")},
+			want:  "orgUnitPath='/Sales/EMEA'",
+		},
+		{
+			name:  "quotes in string values are escaped",
+			quals: []*quals.Qual{stringQual("family_name", "=", "O'Brien")},
+			want:  `familyName='O\'Brien'`,
+		},
+		{
+			name: "quals are combined with the raw query",
+			quals: []*quals.Qual{
+				stringQual("query", "=", "isEnrolledIn2Sv=false"),
+				stringQual("given_name", "=", "Jane"),
+				boolQual("is_admin", "=", false),
+			},
+			want: "isAdmin=false givenName='Jane' isEnrolledIn2Sv=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildDirectoryUsersQuery(newQualsTestQueryData(tt.quals...)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDirectoryUserRequestFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		want    string
+	}{
+		{
+			name: "no columns",
+			want: "id",
+		},
+		{
+			name:    "columns are converted to lower camel case",
+			columns: []string{"primary_email", "is_enrolled_in_2sv", "org_unit_path", "custom_schemas"},
+			want:    "id,primaryEmail,isEnrolledIn2Sv,orgUnitPath,customSchemas",
+		},
+		{
+			name:    "columns without a field of their own share one",
+			columns: []string{"given_name", "family_name", "full_name", "domain", "primary_email"},
+			want:    "id,name,primaryEmail",
+		},
+		{
+			name:    "renamed columns",
+			columns: []string{"is_suspended", "never_logged_in", "last_login_time"},
+			want:    "id,suspended,lastLoginTime",
+		},
+		{
+			name:    "columns not returned by the API are skipped",
+			columns: []string{"id", "query", "view_type", "hash_function", "password", "_ctx"},
+			want:    "id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildDirectoryUserRequestFields(tt.columns); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
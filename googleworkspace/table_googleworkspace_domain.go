@@ -0,0 +1,133 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceDomain(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_domain",
+		Description: "Retrieve the domains of the Google Workspace account, and whether they are verified.",
+		List: &plugin.ListConfig{
+			Hydrate: listDomains,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("domain_name"),
+			Hydrate:    getDomain,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getResolvedCustomerID,
+				Tags: map[string]string{"service": "admin", "action": "get"},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "domain_name",
+				Description: "The name of the domain.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DomainName"),
+			},
+			{
+				Name:        "is_primary",
+				Description: "Indicates if the domain is the primary domain of the customer.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsPrimary"),
+			},
+			{
+				Name:        "verified",
+				Description: "Indicates if the ownership of the domain is verified.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Verified"),
+			},
+			{
+				Name:        "creation_time",
+				Description: "The time the domain was added to the account.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("CreationTime").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "domain_aliases",
+				Description: "The aliases of the domain.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("DomainAliases"),
+			},
+			{
+				Name:        "customer_id",
+				Description: "The unique ID of the customer that owns the domain.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getResolvedCustomerID,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listDomains(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDomainReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := service.Domains.List(getCustomerID(d)).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, domain := range resp.Domains {
+		d.StreamListItem(ctx, domain)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getDomain(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	domainName := d.EqualsQualString("domain_name")
+
+	if domainName == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDomainReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("domainName,isPrimary,verified,creationTime,domainAliases,etag,kind")
+
+	domain, err := service.Domains.Get(getCustomerID(d), domainName).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,138 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceDomainAlias(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_domain_alias",
+		Description: "Retrieve the domain aliases of the Google Workspace account, and whether they are verified.",
+		List: &plugin.ListConfig{
+			Hydrate: listDomainAliases,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "parent_domain_name",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("domain_alias_name"),
+			Hydrate:    getDomainAlias,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getResolvedCustomerID,
+				Tags: map[string]string{"service": "admin", "action": "get"},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "domain_alias_name",
+				Description: "The name of the domain alias.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DomainAliasName"),
+			},
+			{
+				Name:        "parent_domain_name",
+				Description: "The name of the domain the alias is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ParentDomainName"),
+			},
+			{
+				Name:        "verified",
+				Description: "Indicates if the ownership of the domain alias is verified.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Verified"),
+			},
+			{
+				Name:        "creation_time",
+				Description: "The time the domain alias was added to the account.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("CreationTime").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "customer_id",
+				Description: "The unique ID of the customer that owns the domain alias.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getResolvedCustomerID,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listDomainAliases(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDomainReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	req := service.DomainAliases.List(getCustomerID(d))
+	if parentDomainName := d.EqualsQualString("parent_domain_name"); parentDomainName != "" {
+		req = req.ParentDomainName(parentDomainName)
+	}
+
+	resp, err := req.Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, domainAlias := range resp.DomainAliases {
+		d.StreamListItem(ctx, domainAlias)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getDomainAlias(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	domainAliasName := d.EqualsQualString("domain_alias_name")
+
+	if domainAliasName == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDomainReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("domainAliasName,parentDomainName,verified,creationTime,etag,kind")
+
+	domainAlias, err := service.DomainAliases.Get(getCustomerID(d), domainAliasName).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return domainAlias, nil
+}
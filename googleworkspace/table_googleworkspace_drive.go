@@ -24,6 +24,7 @@ func tableGoogleWorkspaceDrive(_ context.Context) *plugin.Table {
 		Description: "Drives defined user's shared drives in the Google Drive.",
 		List: &plugin.ListConfig{
 			Hydrate: listDrives,
+			Tags:    map[string]string{"service": "drive", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "name",
@@ -47,6 +48,7 @@ func tableGoogleWorkspaceDrive(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.SingleColumn("id"),
 			Hydrate:    getDrive,
+			Tags:       map[string]string{"service": "drive", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -207,6 +209,9 @@ func listDrives(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData)
 
 	resp := service.Drives.List().Fields(requiredFields...).Q(query).UseDomainAdminAccess(useDomainAdminAccess).PageSize(pageSize)
 	if err := resp.Pages(ctx, func(page *drive.DriveList) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, data := range page.Drives {
 			parsedTime, _ := time.Parse(time.RFC3339, data.CreatedTime)
 			data.CreatedTime = parsedTime.Format(time.RFC3339)
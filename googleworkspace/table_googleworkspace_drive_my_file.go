@@ -316,6 +316,7 @@ func tableGoogleWorkspaceDriveMyFile(_ context.Context) *plugin.Table {
 		Description: "Retrieves file's metadata or content owned by an user.",
 		List: &plugin.ListConfig{
 			Hydrate: listDriveMyFiles,
+			Tags:    map[string]string{"service": "drive", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "name",
@@ -335,13 +336,27 @@ func tableGoogleWorkspaceDriveMyFile(_ context.Context) *plugin.Table {
 					Name:    "query",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
 			},
 		},
 		Get: &plugin.GetConfig{
-			KeyColumns: plugin.SingleColumn("id"),
-			Hydrate:    getDriveMyFile,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "id",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+			Hydrate: getDriveMyFile,
+			Tags:    map[string]string{"service": "drive", "action": "get"},
 		},
-		Columns: driveFileColumns(),
+		Columns: append(driveFileColumns(), impersonateUserColumn()),
 	}
 }
 
@@ -426,6 +441,9 @@ func listDriveMyFiles(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 	// Use "*" to return all fields
 	resp := service.Files.List().Fields(requiredFields...).Q(query).PageSize(maxResult)
 	if err := resp.Pages(ctx, func(page *drive.FileList) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, file := range page.Files {
 			parsedTime, _ := time.Parse(time.RFC3339, file.CreatedTime)
 			file.CreatedTime = parsedTime.Format(time.RFC3339)
@@ -486,8 +504,8 @@ func buildDriveFileRequestFields(ctx context.Context, queryColumns []string) []g
 	}
 
 	for _, columnName := range queryColumns {
-		// Optional columns
-		if columnName == "query" || columnName == "_ctx" {
+		// Optional columns, which are not fields of the file
+		if columnName == "query" || columnName == "impersonate_user" || columnName == "_ctx" {
 			continue
 		}
 
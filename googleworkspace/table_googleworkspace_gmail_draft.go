@@ -21,6 +21,7 @@ func tableGoogleWorkspaceGmailDraft(_ context.Context) *plugin.Table {
 		Description: "Retrieves draft messages in the specified user's mailbox.",
 		List: &plugin.ListConfig{
 			Hydrate: listGmailDrafts,
+			Tags:    map[string]string{"service": "gmail", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "user_id",
@@ -35,6 +36,7 @@ func tableGoogleWorkspaceGmailDraft(_ context.Context) *plugin.Table {
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.AllColumns([]string{"draft_id", "user_id"}),
 			Hydrate:    getGmailDraft,
+			Tags:       map[string]string{"service": "gmail", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -179,6 +181,9 @@ func listGmailDrafts(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrate
 
 	resp := service.Users.Drafts.List(userID).Q(query).MaxResults(maxResults)
 	if err := resp.Pages(ctx, func(page *gmail.ListDraftsResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, draft := range page.Drafts {
 			d.StreamListItem(ctx, draft)
 
@@ -22,6 +22,7 @@ func tableGoogleWorkspaceGmailMessage(_ context.Context) *plugin.Table {
 		Description: "Retrieves messages in the specified user's mailbox.",
 		List: &plugin.ListConfig{
 			Hydrate: listGmailMessages,
+			Tags:    map[string]string{"service": "gmail", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "user_id",
@@ -43,8 +44,9 @@ func tableGoogleWorkspaceGmailMessage(_ context.Context) *plugin.Table {
 			},
 		},
 		Get: &plugin.GetConfig{
-			KeyColumns: 	plugin.AllColumns([]string{"id", "user_id"}),
-			Hydrate:    	getGmailMessage,
+			KeyColumns:     plugin.AllColumns([]string{"id", "user_id"}),
+			Hydrate:        getGmailMessage,
+			Tags:           map[string]string{"service": "gmail", "action": "get"},
 			MaxConcurrency: 50,
 		},
 		Columns: []*plugin.Column{
@@ -188,6 +190,9 @@ func listGmailMessages(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydra
 
 	resp := service.Users.Messages.List(userID).Q(query).MaxResults(maxResults)
 	if err := resp.Pages(ctx, func(page *gmail.ListMessagesResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, message := range page.Messages {
 			d.StreamListItem(ctx, message)
 
@@ -21,6 +21,7 @@ func tableGoogleWorkspaceGmailMyDraft(_ context.Context) *plugin.Table {
 		Description: "Retrieves draft messages in the current authenticated user's mailbox.",
 		List: &plugin.ListConfig{
 			Hydrate: listGmailMyDrafts,
+			Tags:    map[string]string{"service": "gmail", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:      "message_internal_date",
@@ -31,11 +32,25 @@ func tableGoogleWorkspaceGmailMyDraft(_ context.Context) *plugin.Table {
 					Name:    "query",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
 			},
 		},
 		Get: &plugin.GetConfig{
-			KeyColumns: plugin.SingleColumn("draft_id"),
-			Hydrate:    getGmailMyDraft,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "draft_id",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+			Hydrate: getGmailMyDraft,
+			Tags:    map[string]string{"service": "gmail", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -111,6 +126,7 @@ func tableGoogleWorkspaceGmailMyDraft(_ context.Context) *plugin.Table {
 				Hydrate:     getGmailMyDraft,
 				Transform:   transform.FromField("Message.Payload"),
 			},
+			impersonateUserColumn(),
 		},
 	}
 }
@@ -169,6 +185,9 @@ func listGmailMyDrafts(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydra
 
 	resp := service.Users.Drafts.List("me").Q(query).MaxResults(maxResults)
 	if err := resp.Pages(ctx, func(page *gmail.ListDraftsResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, draft := range page.Drafts {
 			d.StreamListItem(ctx, draft)
 
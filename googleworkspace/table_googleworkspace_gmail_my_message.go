@@ -21,6 +21,7 @@ func tableGoogleWorkspaceGmailMyMessage(_ context.Context) *plugin.Table {
 		Description: "Retrieves messages in the current authenticated user's mailbox.",
 		List: &plugin.ListConfig{
 			Hydrate: listGmailMyMessages,
+			Tags:    map[string]string{"service": "gmail", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "sender_email",
@@ -35,11 +36,25 @@ func tableGoogleWorkspaceGmailMyMessage(_ context.Context) *plugin.Table {
 					Name:    "query",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
 			},
 		},
 		Get: &plugin.GetConfig{
-			KeyColumns: 	plugin.SingleColumn("id"),
-			Hydrate:    	getGmailMyMessage,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "id",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+			Hydrate:        getGmailMyMessage,
+			Tags:           map[string]string{"service": "gmail", "action": "get"},
 			MaxConcurrency: 50,
 		},
 		Columns: []*plugin.Column{
@@ -109,6 +124,7 @@ func tableGoogleWorkspaceGmailMyMessage(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_JSON,
 				Hydrate:     getGmailMyMessage,
 			},
+			impersonateUserColumn(),
 		},
 	}
 }
@@ -172,6 +188,9 @@ func listGmailMyMessages(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 
 	resp := service.Users.Messages.List("me").Q(query).MaxResults(maxResults)
 	if err := resp.Pages(ctx, func(page *gmail.ListMessagesResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, message := range page.Messages {
 			d.StreamListItem(ctx, message)
 
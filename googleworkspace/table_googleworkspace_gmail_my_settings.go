@@ -17,6 +17,39 @@ func tableGoogleWorkspaceGmailMySettings(_ context.Context) *plugin.Table {
 		Description: "Retrieves settings for the current authenticated user account.",
 		List: &plugin.ListConfig{
 			Hydrate: listGmailMyUser,
+			Tags:    map[string]string{"service": "gmail", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getGmailMyLanguage,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailMyAutoForwardingSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: listGmailMyDelegateSettings,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailMyImapSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailMyPopSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailMyVacationSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -66,6 +99,7 @@ func tableGoogleWorkspaceGmailMySettings(_ context.Context) *plugin.Table {
 				Hydrate:     getGmailMyVacationSetting,
 				Transform:   transform.FromValue(),
 			},
+			impersonateUserColumn(),
 		},
 	}
 }
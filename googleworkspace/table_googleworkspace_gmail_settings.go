@@ -19,8 +19,35 @@ func tableGoogleWorkspaceGmailSettings(_ context.Context) *plugin.Table {
 		Description: "Retrieves settings for the specified account.",
 		List: &plugin.ListConfig{
 			Hydrate:    listGmailUsers,
+			Tags:       map[string]string{"service": "gmail", "action": "list"},
 			KeyColumns: plugin.SingleColumn("user_email"),
 		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getGmailLanguage,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailSettingAutoForwarding,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: listGmailDelegateSettings,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailSettingImap,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailPopSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+			{
+				Func: getGmailVacationSetting,
+				Tags: map[string]string{"service": "gmail", "action": "get"},
+			},
+		},
 		Columns: []*plugin.Column{
 			{
 				Name:        "user_email",
@@ -0,0 +1,470 @@
+package googleworkspace
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// Ranks of the roles of group members, from the least to the most privileged
+var groupMemberRoleRanks = map[string]int{
+	"MEMBER":  1,
+	"MANAGER": 2,
+	"OWNER":   3,
+}
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceGroupMemberTransitive(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_group_member_transitive",
+		Description: "Retrieve the effective members of groups in the Google Workspace directory, including the members of nested groups.",
+		List: &plugin.ListConfig{
+			Hydrate: listGroupMembersTransitive,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "group_key",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "member_key",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "group_key",
+				Description: "The unique identifier of the group (email or ID).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the group's email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("GroupKey").Transform(emailDomain),
+			},
+			{
+				Name:        "member_key",
+				Description: "The member's email address or unique ID.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "id",
+				Description: "The unique ID of the member.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "email",
+				Description: "The member's email address.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of member (USER, CUSTOMER).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The member's status in the group it is a direct member of.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "role",
+				Description: "The most privileged effective role of the member in the group (OWNER, MANAGER, MEMBER). Members of a nested group have the role of the nested group in the group.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "depth",
+				Description: "The number of groups between the group and the member, on the shortest membership path. Direct members have a depth of 1.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "path",
+				Description: "The emails of the groups on the shortest membership path, from the group to the group the member is a direct member of.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "error",
+				Description: "The error returned when listing the members of the group, or of one of its nested groups, or when getting the membership of the member in one of its groups, if any. Rows with an error have no member details, and indicate that members of the group are missing from the results.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+// TransitiveMember is an effective member of a group, through any number of nested groups
+type TransitiveMember struct {
+	GroupKey  string
+	MemberKey string
+	Id        string
+	Email     string
+	Type      string
+	Status    string
+	Role      string
+	Depth     int
+	Path      []string
+	Error     string
+}
+
+// groupMembersCache caches the direct members of the groups expanded by a query, since nested
+// groups are usually expanded for several groups
+type groupMembersCache struct {
+	mu      sync.Mutex
+	members map[string][]*admin.Member
+}
+
+//// LIST FUNCTION
+
+func listGroupMembersTransitive(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	groupKey := d.EqualsQualString("group_key")
+	memberKey := d.EqualsQualString("member_key")
+	cache := &groupMembersCache{members: map[string][]*admin.Member{}}
+
+	switch {
+	// Expand the given group
+	case groupKey != "":
+		if err := expandGroupMembers(ctx, d, service, cache, groupKey, memberKey); err != nil {
+			return nil, err
+		}
+
+	// Walk up the groups the member belongs to, which is much cheaper than expanding every group
+	case memberKey != "":
+		if err := listTransitiveGroupsOfMember(ctx, d, service, memberKey); err != nil {
+			return nil, err
+		}
+
+	// Expand every group concurrently, while paging through the groups
+	default:
+		pool := newFanOutPool(ctx, d)
+		defer pool.Wait()
+
+		for _, domain := range getDomains(d) {
+			groupsReq := service.Groups.List().Fields("nextPageToken,groups(id,email)").MaxResults(200)
+			if domain != "" {
+				groupsReq = groupsReq.Domain(domain)
+			} else {
+				groupsReq = groupsReq.Customer(getCustomerID(d))
+			}
+
+			err = groupsReq.Pages(ctx, func(page *admin.Groups) error {
+				// Apply the list rate limiters to each page, before the next one is requested
+				d.WaitForListRateLimit(ctx)
+
+				for _, group := range page.Groups {
+					scheduled := pool.Go(func() {
+						err := expandGroupMembers(ctx, d, service, cache, group.Email, "")
+						if err == nil || isIgnorableFanOutError(err) {
+							return
+						}
+
+						// Stream a row with the error, so that the listing is known to be incomplete
+						plugin.Logger(ctx).Warn("googleworkspace_group_member_transitive.listGroupMembersTransitive", "group", group.Email, "error", err)
+						d.StreamListItem(ctx, &TransitiveMember{
+							GroupKey: group.Email,
+							Error:    err.Error(),
+						})
+					})
+					if !scheduled {
+						page.NextPageToken = ""
+						break
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if d.RowsRemaining(ctx) == 0 {
+				break
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// expandGroupMembers streams the effective members of the given group, walking down its nested
+// groups breadth first. If memberKey is set, only the matching member is streamed.
+func expandGroupMembers(ctx context.Context, d *plugin.QueryData, service *admin.Service, cache *groupMembersCache, groupKey string, memberKey string) error {
+	type queueItem struct {
+		groupKey string
+		// The groups from the expanded group down to this one
+		path []string
+		// The role of the nested group in the expanded group, which its members inherit
+		role string
+	}
+
+	results := map[string]*TransitiveMember{}
+	var order []string
+
+	// A nested group is only expanded once per inherited role
+	visited := map[string]bool{}
+	queue := []queueItem{{groupKey: groupKey, path: []string{groupKey}}}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		members, err := cache.list(ctx, d, service, item.groupKey)
+		if err != nil {
+			// Members of the expanded group itself are all missing
+			if len(item.path) == 1 {
+				return err
+			}
+
+			// Stream a row with the error, so that the listing is known to be incomplete
+			plugin.Logger(ctx).Warn("googleworkspace_group_member_transitive.expandGroupMembers", "group", groupKey, "nested_group", item.groupKey, "error", err)
+			d.StreamListItem(ctx, &TransitiveMember{
+				GroupKey: groupKey,
+				Path:     item.path,
+				Error:    err.Error(),
+			})
+			continue
+		}
+
+		for _, member := range members {
+			role := item.role
+			if role == "" {
+				role = member.Role
+			}
+
+			if member.Type == "GROUP" {
+				// Skip groups already on the path, which are nested in themselves
+				if containsFold(item.path, member.Email) {
+					continue
+				}
+
+				key := strings.ToLower(member.Email) + "/" + role
+				if !visited[key] {
+					visited[key] = true
+					queue = append(queue, queueItem{
+						groupKey: member.Email,
+						path:     append(append([]string{}, item.path...), member.Email),
+						role:     role,
+					})
+				}
+				continue
+			}
+
+			if memberKey != "" && !strings.EqualFold(memberKey, member.Email) && memberKey != member.Id {
+				continue
+			}
+
+			// Members are first found on their shortest path, and keep their most privileged role
+			if result, ok := results[member.Id]; ok {
+				if groupMemberRoleRanks[role] > groupMemberRoleRanks[result.Role] {
+					result.Role = role
+				}
+				continue
+			}
+			results[member.Id] = &TransitiveMember{
+				GroupKey:  groupKey,
+				MemberKey: memberKey,
+				Id:        member.Id,
+				Email:     member.Email,
+				Type:      member.Type,
+				Status:    member.Status,
+				Role:      role,
+				Depth:     len(item.path),
+				Path:      item.path,
+			}
+			order = append(order, member.Id)
+		}
+	}
+
+	for _, id := range order {
+		result := results[id]
+		if result.MemberKey == "" {
+			// Members of type CUSTOMER have no email address
+			result.MemberKey = result.Email
+			if result.MemberKey == "" {
+				result.MemberKey = result.Id
+			}
+		}
+		d.StreamListItem(ctx, result)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// listTransitiveGroupsOfMember streams the member in each group it effectively belongs to,
+// walking up the groups it is a member of breadth first
+func listTransitiveGroupsOfMember(ctx context.Context, d *plugin.QueryData, service *admin.Service, memberKey string) error {
+	type queueItem struct {
+		memberKey string
+		// The groups from the group of this member down to the group the member is a direct member of
+		path []string
+	}
+
+	var member *admin.Member
+	results := map[string]*TransitiveMember{}
+	var order []string
+
+	visited := map[string]bool{}
+	queue := []queueItem{{memberKey: memberKey}}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		groups, err := listGroupsOfMember(ctx, d, service, item.memberKey)
+		if err != nil {
+			return err
+		}
+
+		for _, group := range groups {
+			// Skip groups already on the path, which are nested in themselves
+			if containsFold(item.path, group.Email) {
+				continue
+			}
+
+			// Apply the list rate limiters to each call of the walk up
+			d.WaitForListRateLimit(ctx)
+
+			// The role of the member, or nested group, in the group is the role inherited by the member
+			membership, err := service.Members.Get(group.Email, item.memberKey).Fields("id,email,role,type,status").Context(ctx).Do()
+			if err != nil {
+				if isIgnorableFanOutError(err) {
+					continue
+				}
+
+				// Stream a row with the error, so that the listing is known to be incomplete
+				plugin.Logger(ctx).Warn("googleworkspace_group_member_transitive.listTransitiveGroupsOfMember", "member", item.memberKey, "group", group.Email, "error", err)
+				d.StreamListItem(ctx, &TransitiveMember{
+					GroupKey:  group.Email,
+					MemberKey: memberKey,
+					Path:      append([]string{group.Email}, item.path...),
+					Error:     err.Error(),
+				})
+				if d.RowsRemaining(ctx) == 0 {
+					return nil
+				}
+				continue
+			}
+			if member == nil && item.memberKey == memberKey {
+				member = membership
+			}
+
+			key := strings.ToLower(group.Email)
+			if result, ok := results[key]; ok {
+				if groupMemberRoleRanks[membership.Role] > groupMemberRoleRanks[result.Role] {
+					result.Role = membership.Role
+				}
+			} else {
+				results[key] = &TransitiveMember{
+					GroupKey:  group.Email,
+					MemberKey: memberKey,
+					Role:      membership.Role,
+					Depth:     len(item.path) + 1,
+					Path:      append([]string{group.Email}, item.path...),
+				}
+				order = append(order, key)
+			}
+
+			if !visited[key] {
+				visited[key] = true
+				queue = append(queue, queueItem{memberKey: group.Email, path: append([]string{group.Email}, item.path...)})
+			}
+		}
+	}
+
+	for _, key := range order {
+		result := results[key]
+		if member != nil {
+			result.Id = member.Id
+			result.Email = member.Email
+			result.Type = member.Type
+			result.Status = member.Status
+		}
+		d.StreamListItem(ctx, result)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// listGroupsOfMember returns the groups the given user or group is a direct member of
+func listGroupsOfMember(ctx context.Context, d *plugin.QueryData, service *admin.Service, memberKey string) ([]*admin.Group, error) {
+	var groups []*admin.Group
+
+	req := service.Groups.List().UserKey(memberKey).Fields("nextPageToken,groups(id,email)").MaxResults(200)
+	err := req.Pages(ctx, func(page *admin.Groups) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		groups = append(groups, page.Groups...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// list returns the direct members of the given group
+func (c *groupMembersCache) list(ctx context.Context, d *plugin.QueryData, service *admin.Service, groupKey string) ([]*admin.Member, error) {
+	key := strings.ToLower(groupKey)
+
+	c.mu.Lock()
+	members, ok := c.members[key]
+	c.mu.Unlock()
+	if ok {
+		return members, nil
+	}
+
+	req := service.Members.List(groupKey).Fields(googleapi.Field("nextPageToken,members(id,email,role,type,status)")).MaxResults(200)
+	err := req.Pages(ctx, func(page *admin.Members) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		members = append(members, page.Members...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.members[key] = members
+	c.mu.Unlock()
+
+	return members, nil
+}
+
+// containsFold returns whether the given emails contain the given email, ignoring case
+func containsFold(emails []string, email string) bool {
+	for _, e := range emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 )
 
@@ -18,17 +19,35 @@ func tableGoogleWorkspaceGroupMembers(_ context.Context) *plugin.Table {
 		List: &plugin.ListConfig{
 			// Remove KeyColumns requirement to allow querying all groups
 			Hydrate: listAllGroupMembers,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "group_key",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
 		},
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.AllColumns([]string{"group_key", "member_key"}),
 			Hydrate:    getGroupMember,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
 				Name:        "group_key",
 				Description: "The unique identifier of the group (email or ID).",
 				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromQual("group_key"),
+				Transform:   transform.FromField("GroupKey"),
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the group's email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("GroupKey").Transform(emailDomain),
 			},
 			{
 				Name:        "member_key",
@@ -77,6 +96,11 @@ func tableGoogleWorkspaceGroupMembers(_ context.Context) *plugin.Table {
 				Description: "The type of the API resource.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "error",
+				Description: "The error returned when listing the members of the group, if any. Rows with an error have no member details, and indicate that the members of the group are missing from the results.",
+				Type:        proto.ColumnType_STRING,
+			},
 		},
 	}
 }
@@ -92,6 +116,7 @@ type MemberWithGroup struct {
 	DeliverySettings string `json:"delivery_settings"`
 	Etag             string `json:"etag"`
 	Kind             string `json:"kind"`
+	Error            string `json:"error"`
 }
 
 //// LIST FUNCTION
@@ -107,18 +132,25 @@ func listGroupMembers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 		return nil, err
 	}
 
+	if err := listMembersOfGroup(ctx, d, service, groupKey); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listMembersOfGroup pages through the members of the given group and streams them
+func listMembersOfGroup(ctx context.Context, d *plugin.QueryData, service *admin.Service, groupKey string) error {
 	// Specify fields to retrieve
-	fields := googleapi.Field("members(id,email,role,type,status,delivery_settings,etag,kind)")
+	fields := googleapi.Field("nextPageToken,members(id,email,role,type,status,deliverySettings,etag,kind)")
 
 	req := service.Members.List(groupKey).Fields(fields).MaxResults(200)
 
-	for {
-		resp, err := req.Do()
-		if err != nil {
-			return nil, err
-		}
+	return req.Pages(ctx, func(page *admin.Members) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
 
-		for _, member := range resp.Members {
+		for _, member := range page.Members {
 			memberWithGroup := &MemberWithGroup{
 				GroupKey:         groupKey,
 				Id:               member.Id,
@@ -135,17 +167,12 @@ func listGroupMembers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydrat
 
 			// Check if we should continue processing
 			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
+				page.NextPageToken = ""
+				break
 			}
 		}
-
-		if resp.NextPageToken == "" {
-			break
-		}
-		req.PageToken(resp.NextPageToken)
-	}
-
-	return nil, nil
+		return nil
+	})
 }
 
 //// GET FUNCTION
@@ -164,7 +191,7 @@ func getGroupMember(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateD
 	}
 
 	// Specify fields to retrieve
-	fields := googleapi.Field("id,email,role,type,status,delivery_settings,etag,kind")
+	fields := googleapi.Field("id,email,role,type,status,deliverySettings,etag,kind")
 
 	member, err := service.Members.Get(groupKey, memberKey).Fields(fields).Do()
 	if err != nil {
@@ -201,46 +228,49 @@ func listAllGroupMembers(ctx context.Context, d *plugin.QueryData, _ *plugin.Hyd
 		return nil, err
 	}
 
-	// First get all groups
-	groupsReq := service.Groups.List().Customer("my_customer").Fields("groups(id,email)")
-	groupsResp, err := groupsReq.Do()
-	if err != nil {
-		return nil, err
-	}
-
-	// Then get members for each group
-	memberFields := googleapi.Field("members(id,email,role,type,status,delivery_settings,etag,kind)")
+	// List the members of each group concurrently, while paging through the groups
+	pool := newFanOutPool(ctx, d)
+	defer pool.Wait()
 
-	for _, group := range groupsResp.Groups {
-		if d.RowsRemaining(ctx) == 0 {
-			break
+	for _, domain := range getDomains(d) {
+		groupsReq := service.Groups.List().Fields("nextPageToken,groups(id,email)").MaxResults(200)
+		if domain != "" {
+			groupsReq = groupsReq.Domain(domain)
+		} else {
+			groupsReq = groupsReq.Customer(getCustomerID(d))
 		}
 
-		membersReq := service.Members.List(group.Email).Fields(memberFields).MaxResults(200)
-		membersResp, err := membersReq.Do()
+		err = groupsReq.Pages(ctx, func(page *admin.Groups) error {
+			// Apply the list rate limiters to each page, before the next one is requested
+			d.WaitForListRateLimit(ctx)
+
+			for _, group := range page.Groups {
+				scheduled := pool.Go(func() {
+					err := listMembersOfGroup(ctx, d, service, group.Email)
+					if err == nil || isIgnorableFanOutError(err) {
+						return
+					}
+
+					// Stream a row with the error, so that the listing is known to be incomplete
+					plugin.Logger(ctx).Warn("googleworkspace_group_members.listAllGroupMembers", "group", group.Email, "error", err)
+					d.StreamListItem(ctx, &MemberWithGroup{
+						GroupKey: group.Email,
+						Error:    err.Error(),
+					})
+				})
+				if !scheduled {
+					page.NextPageToken = ""
+					break
+				}
+			}
+			return nil
+		})
 		if err != nil {
-			// Skip groups without members or access denied
-			continue
+			return nil, err
 		}
 
-		for _, member := range membersResp.Members {
-			memberWithGroup := &MemberWithGroup{
-				GroupKey:         group.Email,
-				Id:               member.Id,
-				Email:            member.Email,
-				Role:             member.Role,
-				Type:             member.Type,
-				Status:           member.Status,
-				DeliverySettings: member.DeliverySettings,
-				Etag:             member.Etag,
-				Kind:             member.Kind,
-			}
-
-			d.StreamListItem(ctx, memberWithGroup)
-
-			if d.RowsRemaining(ctx) == 0 {
-				break
-			}
+		if d.RowsRemaining(ctx) == 0 {
+			break
 		}
 	}
 
@@ -0,0 +1,254 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/groupssettings/v1"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceGroupSettings(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_group_settings",
+		Description: "Retrieve the access and posting settings of groups in the Google Workspace directory.",
+		List: &plugin.ListConfig{
+			ParentHydrate: listGroups,
+			Hydrate:       listGroupSettings,
+			Tags:          map[string]string{"service": "groupssettings", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("email"),
+			Hydrate:    getGroupSettings,
+			Tags:       map[string]string{"service": "groupssettings", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "email",
+				Description: "The email address of the group.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the group's email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Email").Transform(emailDomain),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the group.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.Name"),
+			},
+			{
+				Name:        "who_can_join",
+				Description: "Who can join the group (ANYONE_CAN_JOIN, ALL_IN_DOMAIN_CAN_JOIN, INVITED_CAN_JOIN, CAN_REQUEST_TO_JOIN).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanJoin"),
+			},
+			{
+				Name:        "who_can_post_message",
+				Description: "Who can post a message to the group (NONE_CAN_POST, ALL_MANAGERS_CAN_POST, ALL_MEMBERS_CAN_POST, ALL_OWNERS_CAN_POST, ALL_IN_DOMAIN_CAN_POST, ANYONE_CAN_POST).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanPostMessage"),
+			},
+			{
+				Name:        "who_can_view_membership",
+				Description: "Who can view the members of the group (ALL_IN_DOMAIN_CAN_VIEW, ALL_MEMBERS_CAN_VIEW, ALL_MANAGERS_CAN_VIEW, ALL_OWNERS_CAN_VIEW).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanViewMembership"),
+			},
+			{
+				Name:        "who_can_view_group",
+				Description: "Who can view the messages of the group (ANYONE_CAN_VIEW, ALL_IN_DOMAIN_CAN_VIEW, ALL_MEMBERS_CAN_VIEW, ALL_MANAGERS_CAN_VIEW, ALL_OWNERS_CAN_VIEW).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanViewGroup"),
+			},
+			{
+				Name:        "who_can_discover_group",
+				Description: "Who can find the group in a search (ANYONE_CAN_DISCOVER, ALL_IN_DOMAIN_CAN_DISCOVER, ALL_MEMBERS_CAN_DISCOVER).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanDiscoverGroup"),
+			},
+			{
+				Name:        "who_can_contact_owner",
+				Description: "Who can contact the owners of the group (ANYONE_CAN_CONTACT, ALL_IN_DOMAIN_CAN_CONTACT, ALL_MEMBERS_CAN_CONTACT, ALL_MANAGERS_CAN_CONTACT).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanContactOwner"),
+			},
+			{
+				Name:        "who_can_leave_group",
+				Description: "Who can leave the group (ALL_MANAGERS_CAN_LEAVE, ALL_MEMBERS_CAN_LEAVE, NONE_CAN_LEAVE).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanLeaveGroup"),
+			},
+			{
+				Name:        "who_can_moderate_members",
+				Description: "Who can add, remove, ban and approve members of the group (ALL_MEMBERS, OWNERS_AND_MANAGERS, OWNERS_ONLY, NONE).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanModerateMembers"),
+			},
+			{
+				Name:        "who_can_moderate_content",
+				Description: "Who can moderate the messages of the group (ALL_MEMBERS, OWNERS_AND_MANAGERS, OWNERS_ONLY, NONE).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.WhoCanModerateContent"),
+			},
+			{
+				Name:        "allow_external_members",
+				Description: "Indicates if users outside of the Google Workspace account can be members of the group.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.AllowExternalMembers").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "allow_web_posting",
+				Description: "Indicates if members can post to the group from the web, instead of only by email.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.AllowWebPosting").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "archive_only",
+				Description: "Indicates if the group is archive only, in which case no one can post to it.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.ArchiveOnly").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "is_archived",
+				Description: "Indicates if the messages of the group are archived.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.IsArchived").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "members_can_post_as_the_group",
+				Description: "Indicates if members can post messages using the group's email address as the sender.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.MembersCanPostAsTheGroup").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "include_in_global_address_list",
+				Description: "Indicates if the group is included in the Global Address List.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.IncludeInGlobalAddressList").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "enable_collaborative_inbox",
+				Description: "Indicates if the collaborative inbox is enabled for the group.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Settings.EnableCollaborativeInbox").NullIfZero().Transform(transform.ToBool),
+			},
+			{
+				Name:        "message_moderation_level",
+				Description: "The moderation level of incoming messages (MODERATE_ALL_MESSAGES, MODERATE_NON_MEMBERS, MODERATE_NEW_MEMBERS, MODERATE_NONE).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.MessageModerationLevel"),
+			},
+			{
+				Name:        "spam_moderation_level",
+				Description: "The moderation level of messages detected as spam (ALLOW, MODERATE, SILENTLY_MODERATE, REJECT).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.SpamModerationLevel"),
+			},
+			{
+				Name:        "reply_to",
+				Description: "The default recipient of replies to messages (REPLY_TO_CUSTOM, REPLY_TO_SENDER, REPLY_TO_LIST, REPLY_TO_OWNER, REPLY_TO_IGNORE, REPLY_TO_MANAGERS).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.ReplyTo"),
+			},
+			{
+				Name:        "custom_reply_to",
+				Description: "The email address replies are sent to, when reply_to is REPLY_TO_CUSTOM.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.CustomReplyTo"),
+			},
+			{
+				Name:        "default_sender",
+				Description: "The default sender of messages posted by members (DEFAULT_SELF, GROUP).",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.DefaultSender"),
+			},
+			{
+				Name:        "primary_language",
+				Description: "The primary language of the group.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Settings.PrimaryLanguage"),
+			},
+			{
+				Name:        "error",
+				Description: "The error returned when getting the settings of the group, if any. Rows with an error have no settings.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+// GroupSettings holds the settings of a group, or the error returned when getting them
+type GroupSettings struct {
+	Email    string
+	Settings *groupssettings.Groups
+	Error    string
+}
+
+//// LIST FUNCTION
+
+func listGroupSettings(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	group := h.Item.(*admin.Group)
+
+	settings, err := getSettingsOfGroup(ctx, d, group.Email)
+	if err != nil {
+		if isIgnorableFanOutError(err) {
+			return nil, nil
+		}
+
+		// Stream a row with the error, so that the listing is known to be incomplete
+		plugin.Logger(ctx).Warn("googleworkspace_group_settings.listGroupSettings", "group", group.Email, "error", err)
+		d.StreamListItem(ctx, &GroupSettings{
+			Email: group.Email,
+			Error: err.Error(),
+		})
+		return nil, nil
+	}
+
+	d.StreamListItem(ctx, settings)
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getGroupSettings(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	email := d.EqualsQualString("email")
+
+	if email == "" {
+		return nil, nil
+	}
+
+	return getSettingsOfGroup(ctx, d, email)
+}
+
+// getSettingsOfGroup gets the settings of the group with the given email address
+func getSettingsOfGroup(ctx context.Context, d *plugin.QueryData, email string) (*GroupSettings, error) {
+	service, err := GroupsSettingsService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := service.Groups.Get(email).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupSettings{
+		Email:    email,
+		Settings: settings,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 )
 
@@ -17,10 +18,30 @@ func tableGoogleWorkspaceGroups(_ context.Context) *plugin.Table {
 		Description: "Retrieve groups in the Google Workspace directory.",
 		List: &plugin.ListConfig{
 			Hydrate: listGroups,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "member_key",
+					Require: plugin.Optional,
+				},
+			},
 		},
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.SingleColumn("id"),
 			Hydrate:    getGroup,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getGroupMemberRole,
+				Tags: map[string]string{"service": "admin", "action": "get"},
+				// The member_key is not a member of the group fetched by id
+				ShouldIgnoreError: isNotFoundError([]string{"404"}),
+			},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -33,6 +54,12 @@ func tableGoogleWorkspaceGroups(_ context.Context) *plugin.Table {
 				Description: "The email address of the group.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "domain",
+				Description: "The domain of the group's email address.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Email").Transform(emailDomain),
+			},
 			{
 				Name:        "name",
 				Description: "The display name of the group.",
@@ -66,6 +93,19 @@ func tableGoogleWorkspaceGroups(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_JSON,
 				Transform:   transform.FromField("NonEditableAliases"),
 			},
+			{
+				Name:        "member_key",
+				Description: "The email address or unique ID of a user or group, to list only the groups it is a direct member of.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("member_key"),
+			},
+			{
+				Name:        "role",
+				Description: "The role of the member_key in the group (OWNER, MANAGER, MEMBER), if member_key is set.",
+				Type:        proto.ColumnType_STRING,
+				Hydrate:     getGroupMemberRole,
+				Transform:   transform.FromField("Role"),
+			},
 			{
 				Name:        "etag",
 				Description: "The ETag of the resource.",
@@ -89,29 +129,51 @@ func listGroups(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData)
 	}
 
 	// Specify fields to retrieve
-	fields := googleapi.Field("groups(id,email,name,description,directMembersCount,adminCreated,aliases,nonEditableAliases,etag,kind)")
+	fields := googleapi.Field("nextPageToken,groups(id,email,name,description,directMembersCount,adminCreated,aliases,nonEditableAliases,etag,kind)")
 
-	req := service.Groups.List().Customer("my_customer").Fields(fields).MaxResults(200)
+	// List only the groups the given user or group is a direct member of, in any domain
+	domains := getDomains(d)
+	memberKey := d.EqualsQualString("member_key")
+	if memberKey != "" {
+		domains = []string{""}
+	}
 
-	for {
-		resp, err := req.Do()
-		if err != nil {
-			return nil, err
+	for _, domain := range domains {
+		req := service.Groups.List().Fields(fields).MaxResults(200)
+
+		// List groups of the given domain, or of all domains of the customer
+		switch {
+		case memberKey != "":
+			req = req.UserKey(memberKey)
+		case domain != "":
+			req = req.Domain(domain)
+		default:
+			req = req.Customer(getCustomerID(d))
 		}
 
-		for _, group := range resp.Groups {
-			d.StreamListItem(ctx, group)
+		for {
+			resp, err := req.Do()
+			if err != nil {
+				return nil, err
+			}
+
+			// Apply the list rate limiters to each page, before the next one is requested
+			d.WaitForListRateLimit(ctx)
 
-			// Check if we should continue processing
-			if d.RowsRemaining(ctx) == 0 {
-				return nil, nil
+			for _, group := range resp.Groups {
+				d.StreamListItem(ctx, group)
+
+				// Check if we should continue processing
+				if d.RowsRemaining(ctx) == 0 {
+					return nil, nil
+				}
 			}
-		}
 
-		if resp.NextPageToken == "" {
-			break
+			if resp.NextPageToken == "" {
+				break
+			}
+			req.PageToken(resp.NextPageToken)
 		}
-		req.PageToken(resp.NextPageToken)
 	}
 
 	return nil, nil
@@ -141,3 +203,27 @@ func getGroup(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (
 
 	return group, nil
 }
+
+//// HYDRATE FUNCTIONS
+
+func getGroupMemberRole(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	memberKey := d.EqualsQualString("member_key")
+
+	if memberKey == "" {
+		return nil, nil
+	}
+
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	group := h.Item.(*admin.Group)
+
+	member, err := service.Members.Get(group.Id, memberKey).Fields("role").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,335 @@
+package googleworkspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceMobileDevice(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_mobile_device",
+		Description: "Retrieve the mobile devices managed in the Google Workspace account.",
+		List: &plugin.ListConfig{
+			Hydrate: listMobileDevices,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "model",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "os",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "status",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "management_type",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "serial_number",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "query",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("resource_id"),
+			Hydrate:    getMobileDevice,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "resource_id",
+				Description: "The unique ID of the mobile device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ResourceId"),
+			},
+			{
+				Name:        "device_id",
+				Description: "The serial number of a Google Sync mobile device, or a unique identifier for Android devices.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DeviceId"),
+			},
+			{
+				Name:        "serial_number",
+				Description: "The serial number of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("SerialNumber"),
+			},
+			{
+				Name:        "model",
+				Description: "The model of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "manufacturer",
+				Description: "The manufacturer of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "brand",
+				Description: "The brand of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "os",
+				Description: "The operating system of the device, including its version.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "build_number",
+				Description: "The build number of the operating system of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("BuildNumber"),
+			},
+			{
+				Name:        "security_patch_level",
+				Description: "The security patch level of the device.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("SecurityPatchLevel").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "management_type",
+				Description: "The management type of the device (ANDROID, CLASSIC_ANDROID, GOOGLE_SYNC, IOS_SYNC, IOS_ADVANCED...), from the type of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Type"),
+			},
+			{
+				Name:        "status",
+				Description: "The status of the device (APPROVED, PENDING, UNPROVISIONED, BLOCKED, WIPING, WIPED, ...).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "privilege",
+				Description: "The DMAgentPermission of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "device_compromised_status",
+				Description: "Indicates if the device is compromised, e.g. rooted or jailbroken.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DeviceCompromisedStatus"),
+			},
+			{
+				Name:        "encryption_status",
+				Description: "The encryption status of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("EncryptionStatus"),
+			},
+			{
+				Name:        "device_password_status",
+				Description: "The status of the password of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("DevicePasswordStatus"),
+			},
+			{
+				Name:        "first_sync",
+				Description: "The time the device was first synchronized with the policy settings in the Admin console.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("FirstSync").NullIfZero(),
+			},
+			{
+				Name:        "last_sync",
+				Description: "The time the device was last synchronized with the policy settings in the Admin console.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastSync").NullIfZero(),
+			},
+			{
+				Name:        "owner_emails",
+				Description: "The email addresses of the owners of the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Email"),
+			},
+			{
+				Name:        "owner_names",
+				Description: "The names of the owners of the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "adb_status",
+				Description: "Indicates if Android Debug Bridge (ADB) is enabled on the device.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("AdbStatus"),
+			},
+			{
+				Name:        "developer_options_status",
+				Description: "Indicates if developer options are enabled on the device.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("DeveloperOptionsStatus"),
+			},
+			{
+				Name:        "unknown_sources_status",
+				Description: "Indicates if the device allows installing apps from unknown sources.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("UnknownSourcesStatus"),
+			},
+			{
+				Name:        "imei",
+				Description: "The IMEI number of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "meid",
+				Description: "The MEID number of the device.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "wifi_mac_address",
+				Description: "The Wi-Fi MAC address of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("WifiMacAddress"),
+			},
+			{
+				Name:        "network_operator",
+				Description: "The mobile network operator of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("NetworkOperator"),
+			},
+			{
+				Name:        "user_agent",
+				Description: "The user agent of the device.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("UserAgent"),
+			},
+			{
+				Name:        "applications",
+				Description: "The applications installed on an Android device.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "other_accounts_info",
+				Description: "The accounts other than the Google Workspace account added to the device.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("OtherAccountsInfo"),
+			},
+			{
+				Name:        "query",
+				Description: "A search query to filter mobile devices with, using the Admin SDK search syntax, e.g. status:approved.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromQual("query"),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listMobileDevices(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDeviceMobileReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults := int64(100)
+	if d.QueryContext.Limit != nil {
+		if *d.QueryContext.Limit < maxResults {
+			maxResults = *d.QueryContext.Limit
+		}
+	}
+
+	req := service.Mobiledevices.List(getCustomerID(d)).Projection("FULL").MaxResults(maxResults)
+	if query := buildMobileDevicesQuery(d); query != "" {
+		req = req.Query(query)
+	}
+
+	for {
+		resp, err := req.Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		for _, device := range resp.Mobiledevices {
+			d.StreamListItem(ctx, device)
+
+			// Check if we should continue processing
+			if d.RowsRemaining(ctx) == 0 {
+				return nil, nil
+			}
+		}
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken(resp.NextPageToken)
+	}
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getMobileDevice(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	resourceId := d.EqualsQualString("resource_id")
+
+	if resourceId == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryDeviceMobileReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := service.Mobiledevices.Get(getCustomerID(d), resourceId).Projection("FULL").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// buildMobileDevicesQuery composes the quals of the table into a single Admin SDK search query.
+// Values that would need quoting are left for Postgres to filter on.
+func buildMobileDevicesQuery(d *plugin.QueryData) string {
+	var terms []string
+
+	for _, filter := range []struct{ column, field string }{
+		{"model", "model"},
+		{"os", "os"},
+		{"status", "status"},
+		{"management_type", "type"},
+		{"serial_number", "serial"},
+	} {
+		value := d.EqualsQualString(filter.column)
+		if value == "" || strings.ContainsAny(value, " \t\"'") {
+			continue
+		}
+		terms = append(terms, fmt.Sprintf("%s:%s", filter.field, value))
+	}
+
+	// The raw query is combined with the other filters, which are all ANDed together
+	if query := d.EqualsQualString("query"); query != "" {
+		terms = append(terms, query)
+	}
+
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,47 @@
+package googleworkspace
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+func TestBuildMobileDevicesQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		quals []*quals.Qual
+		want  string
+	}{
+		{
+			name: "no quals",
+			want: "",
+		},
+		{
+			name: "columns are mapped to search fields",
+			quals: []*quals.Qual{
+				stringQual("management_type", "=", "ADVANCED"),
+				stringQual("serial_number", "=", "R58M12345"),
+				stringQual("status", "=", "APPROVED"),
+			},
+			want: "status:APPROVED type:ADVANCED serial:R58M12345",
+		},
+		{
+			name:  "values that would need quoting are skipped",
+			quals: []*quals.Qual{stringQual("model", "=", "Pixel 8"), stringQual("os", "=", "Android")},
+			want:  "os:Android",
+		},
+		{
+			name:  "quals are combined with the raw query",
+			quals: []*quals.Qual{stringQual("query", "=", "sync:2024-01-01.."), stringQual("model", "=", "iPhone15,2")},
+			want:  "model:iPhone15,2 sync:2024-01-01..",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildMobileDevicesQuery(newQualsTestQueryData(tt.quals...)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,20 @@ package googleworkspace
 
 import (
 	"context"
+	"strings"
+
+	"github.com/turbot/go-kit/types"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 )
 
+// Fields to retrieve for each organizational unit
+const orgUnitFields = googleapi.Field("orgUnitId,name,description,orgUnitPath,parentOrgUnitId,parentOrgUnitPath,blockInheritance,etag,kind")
+
 //// TABLE DEFINITION
 
 func tableGoogleWorkspaceOrgUnits(_ context.Context) *plugin.Table {
@@ -17,10 +24,45 @@ func tableGoogleWorkspaceOrgUnits(_ context.Context) *plugin.Table {
 		Description: "Retrieve organizational units for a specific customer in the Google Workspace directory.",
 		List: &plugin.ListConfig{
 			Hydrate: listOrgUnits,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "customer_id",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "include_root",
+					Require: plugin.Optional,
+				},
+			},
 		},
 		Get: &plugin.GetConfig{
-			KeyColumns: plugin.SingleColumn("org_unit_path"),
-			Hydrate:    getOrgUnit,
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "org_unit_path",
+					Require: plugin.Required,
+				},
+				{
+					Name:    "customer_id",
+					Require: plugin.Optional,
+				},
+			},
+			Hydrate: getOrgUnit,
+			Tags:    map[string]string{"service": "admin", "action": "get"},
+		},
+		HydrateConfig: []plugin.HydrateConfig{
+			{
+				Func: getOrgUnitCustomerID,
+				Tags: map[string]string{"service": "admin", "action": "get"},
+			},
+			{
+				Func: getOrgUnitChildCount,
+				Tags: map[string]string{"service": "admin", "action": "list"},
+			},
+			{
+				Func: getOrgUnitUserCount,
+				Tags: map[string]string{"service": "admin", "action": "list"},
+			},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -57,6 +99,38 @@ func tableGoogleWorkspaceOrgUnits(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_STRING,
 				Transform:   transform.FromField("ParentOrgUnitPath"),
 			},
+			{
+				Name:        "depth",
+				Description: "The depth of the organizational unit in the tree, where the root organizational unit has a depth of 0.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("OrgUnitPath").Transform(orgUnitDepth),
+			},
+			{
+				Name:        "ancestor_paths",
+				Description: "The full paths of the ancestors of the organizational unit, from the root organizational unit down to its parent.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("OrgUnitPath").Transform(orgUnitAncestorPaths),
+			},
+			{
+				Name:        "child_count",
+				Description: "The number of direct children of the organizational unit.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getOrgUnitChildCount,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "user_count",
+				Description: "The number of users directly in the organizational unit, excluding the users of its children.",
+				Type:        proto.ColumnType_INT,
+				Hydrate:     getOrgUnitUserCount,
+				Transform:   transform.FromValue(),
+			},
+			{
+				Name:        "include_root",
+				Description: "If true, the root organizational unit \"/\" is also listed.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromQual("include_root"),
+			},
 			{
 				Name:        "block_inheritance",
 				Description: "Indicates if the organizational unit blocks policy inheritance.",
@@ -75,46 +149,66 @@ func tableGoogleWorkspaceOrgUnits(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "customer_id",
-				Description: "The customer ID that owns the organizational unit.",
+				Description: "The customer ID that owns the organizational unit. Null if neither configured nor given, and the customer cannot be read to resolve it.",
 				Type:        proto.ColumnType_STRING,
-				Transform:   transform.FromQual("customer_id"),
+				Hydrate:     getOrgUnitCustomerID,
+				Transform:   transform.FromValue(),
 			},
 		},
 	}
 }
 
+// OrgUnit is an organizational unit, along with the number of its direct children if it is known
+// from listing the whole tree
+type OrgUnit struct {
+	admin.OrgUnit
+	ChildCount *int64
+}
+
 //// LIST FUNCTION
 
 func listOrgUnits(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
-	customerId := "my_customer"
-	if d.EqualsQualString("customer_id") != "" {
-		customerId = d.EqualsQualString("customer_id")
-	}
+	customerId := getOrgUnitsCustomerID(d)
 
 	service, err := AdminService(ctx, d)
 	if err != nil {
 		return nil, err
 	}
 
-	fields := googleapi.Field("organizationUnits(orgUnitId,name,description,orgUnitPath,parentOrgUnitId,parentOrgUnitPath,blockInheritance,etag,kind)")
-
-	req := service.Orgunits.List(customerId).Fields(fields)
+	fields := googleapi.Field("organizationUnits(" + orgUnitFields + ")")
 
-	if d.EqualsQualString("org_unit_path") != "" {
-		req = req.OrgUnitPath(d.EqualsQualString("org_unit_path"))
+	// List the whole tree, instead of only the children of the root organizational unit
+	resp, err := service.Orgunits.List(customerId).Type("all").Fields(fields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
 	}
 
-	if d.EqualsQualString("type") != "" {
-		req = req.Type(d.EqualsQualString("type"))
+	// Count the children of each organizational unit, which are all part of the tree
+	childCounts := map[string]int64{}
+	for _, orgUnit := range resp.OrganizationUnits {
+		childCounts[orgUnit.ParentOrgUnitPath]++
 	}
 
-	resp, err := req.Do()
-	if err != nil {
-		return nil, err
+	orgUnits := resp.OrganizationUnits
+
+	// The root organizational unit is never listed, but its ID is the parent ID of its children
+	if d.EqualsQuals["include_root"].GetBoolValue() {
+		root := &admin.OrgUnit{OrgUnitPath: "/"}
+		for _, orgUnit := range resp.OrganizationUnits {
+			if orgUnit.ParentOrgUnitPath == "/" {
+				root, err = service.Orgunits.Get(customerId, orgUnit.ParentOrgUnitId).Fields(orgUnitFields).Context(ctx).Do()
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		orgUnits = append([]*admin.OrgUnit{root}, orgUnits...)
 	}
 
-	for _, orgUnit := range resp.OrganizationUnits {
-		d.StreamListItem(ctx, orgUnit)
+	for _, orgUnit := range orgUnits {
+		childCount := childCounts[orgUnit.OrgUnitPath]
+		d.StreamListItem(ctx, &OrgUnit{OrgUnit: *orgUnit, ChildCount: &childCount})
 
 		if d.RowsRemaining(ctx) == 0 {
 			break
@@ -127,10 +221,7 @@ func listOrgUnits(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 //// GET FUNCTION
 
 func getOrgUnit(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
-	customerId := "my_customer"
-	if d.EqualsQualString("customer_id") != "" {
-		customerId = d.EqualsQualString("customer_id")
-	}
+	customerId := getOrgUnitsCustomerID(d)
 
 	orgUnitPath := d.EqualsQualString("org_unit_path")
 	if orgUnitPath == "" {
@@ -142,12 +233,133 @@ func getOrgUnit(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData)
 		return nil, err
 	}
 
-	fields := googleapi.Field("orgUnitId,name,description,orgUnitPath,parentOrgUnitId,parentOrgUnitPath,blockInheritance,etag,kind")
+	resp, err := service.Orgunits.Get(customerId, orgUnitPath).Fields(orgUnitFields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrgUnit{OrgUnit: *resp}, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+// getOrgUnitCustomerID returns the customer ID the organizational unit was requested for, since it
+// is not part of the API response. Unless given as a qual or configured, the `my_customer` alias is
+// resolved, so that it can be joined with the customer_id of the other Directory tables. Resolving
+// it is best-effort, as it requires a scope that listing organizational units does not.
+func getOrgUnitCustomerID(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	customerId := getOrgUnitsCustomerID(d)
+	if customerId != "my_customer" {
+		return customerId, nil
+	}
+
+	// have we already failed to resolve the customer ID?
+	cacheKey := "googleworkspace.orgunits.customer_id_unresolved"
+	if _, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
+		return nil, nil
+	}
+
+	resolved, err := getResolvedCustomerID(ctx, d, h)
+	if err != nil {
+		// The customer ID is left null if the scope is not authorized, or the customer cannot be read
+		switch connectionCheckStatus(err) {
+		case connectionCheckScopeMissing, connectionCheckPermissionDenied:
+			plugin.Logger(ctx).Warn("googleworkspace_orgunits.getOrgUnitCustomerID", "error", err)
+			d.ConnectionManager.Cache.Set(cacheKey, true)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// getOrgUnitChildCount returns the number of direct children of the organizational unit, which is
+// only requested if the organizational unit was not listed along with the whole tree
+func getOrgUnitChildCount(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	orgUnit := h.Item.(*OrgUnit)
+	if orgUnit.ChildCount != nil {
+		return *orgUnit.ChildCount, nil
+	}
+
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := service.Orgunits.List(getOrgUnitsCustomerID(d)).OrgUnitPath(orgUnit.OrgUnitId).Type("children").Fields("organizationUnits(orgUnitId)").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(len(resp.OrganizationUnits)), nil
+}
 
-	resp, err := service.Orgunits.Get(customerId, orgUnitPath).Fields(fields).Do()
+// getOrgUnitUserCount pages through the users directly in the organizational unit to count them. The
+// orgUnitPath search also matches the users of its descendants, so they are filtered out by path.
+func getOrgUnitUserCount(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	orgUnit := h.Item.(*OrgUnit)
+
+	service, err := AdminService(ctx, d)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp, nil
+	var count int64
+
+	req := service.Users.List().Customer(getOrgUnitsCustomerID(d)).Query("orgUnitPath=" + quoteSearchValue(orgUnit.OrgUnitPath)).Fields("nextPageToken,users(id,orgUnitPath)").MaxResults(500)
+	err = req.Pages(ctx, func(page *admin.Users) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		for _, user := range page.Users {
+			if strings.EqualFold(user.OrgUnitPath, orgUnit.OrgUnitPath) {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return count, nil
+}
+
+// getOrgUnitsCustomerID returns the customer ID given as a qual, or else the configured one
+func getOrgUnitsCustomerID(d *plugin.QueryData) string {
+	if customerId := d.EqualsQualString("customer_id"); customerId != "" {
+		return customerId
+	}
+	return getCustomerID(d)
+}
+
+//// TRANSFORM FUNCTIONS
+
+// orgUnitDepth returns the number of segments of the given organizational unit path
+func orgUnitDepth(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	return len(orgUnitPathSegments(types.SafeString(d.Value))), nil
+}
+
+// orgUnitAncestorPaths returns the paths of the ancestors of the given organizational unit path,
+// from the root down to its parent
+func orgUnitAncestorPaths(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	segments := orgUnitPathSegments(types.SafeString(d.Value))
+
+	ancestors := []string{}
+	for i := range segments {
+		ancestors = append(ancestors, "/"+strings.Join(segments[:i], "/"))
+	}
+
+	return ancestors, nil
+}
+
+// orgUnitPathSegments splits an organizational unit path into the names of its organizational units,
+// which is empty for the root organizational unit
+func orgUnitPathSegments(orgUnitPath string) []string {
+	orgUnitPath = strings.Trim(orgUnitPath, "/")
+	if orgUnitPath == "" {
+		return nil
+	}
+	return strings.Split(orgUnitPath, "/")
 }
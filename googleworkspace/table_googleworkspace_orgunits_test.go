@@ -0,0 +1,55 @@
+package googleworkspace
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestOrgUnitDepth(t *testing.T) {
+	tests := []struct {
+		orgUnitPath interface{}
+		want        int
+	}{
+		{"/", 0},
+		{"/Sales", 1},
+		{"/Sales/EMEA/France", 3},
+		{"/Sales/EMEA/", 2},
+		{nil, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := orgUnitDepth(context.Background(), &transform.TransformData{Value: tt.orgUnitPath})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("orgUnitDepth(%v) = %v, want %v", tt.orgUnitPath, got, tt.want)
+		}
+	}
+}
+
+func TestOrgUnitAncestorPaths(t *testing.T) {
+	tests := []struct {
+		orgUnitPath interface{}
+		want        []string
+	}{
+		{"/", []string{}},
+		{"/Sales", []string{"/"}},
+		{"/Sales/EMEA/France", []string{"/", "/Sales", "/Sales/EMEA"}},
+		{"/Sales/EMEA/", []string{"/", "/Sales"}},
+		{nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := orgUnitAncestorPaths(context.Background(), &transform.TransformData{Value: tt.orgUnitPath})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orgUnitAncestorPaths(%v) = %v, want %v", tt.orgUnitPath, got, tt.want)
+		}
+	}
+}
@@ -143,9 +143,16 @@ func tableGoogleWorkspacePeopleContact(_ context.Context) *plugin.Table {
 		Description: "Contacts owned by the authenticated user.",
 		List: &plugin.ListConfig{
 			Hydrate:           listPeopleContacts,
+			Tags:              map[string]string{"service": "people", "action": "list"},
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
-		},
-		Columns: peopleContacts(),
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: append(peopleContacts(), impersonateUserColumn()),
 	}
 }
 
@@ -181,6 +188,9 @@ func listPeopleContacts(ctx context.Context, d *plugin.QueryData, _ *plugin.Hydr
 
 	resp := service.People.Connections.List("people/me").PersonFields(personFields).PageSize(maxResult)
 	if err := resp.Pages(ctx, func(page *people.ListConnectionsResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, connection := range page.Connections {
 			// Since, 'names', 'birthdays', 'genders' and 'biographies' are singleton fields
 			var conn contacts
@@ -18,11 +18,16 @@ func tableGoogleWorkspacePeopleContactGroup(_ context.Context) *plugin.Table {
 		Description: "Contact groups owned by the authenticated user",
 		List: &plugin.ListConfig{
 			Hydrate: listPeopleContactGroups,
+			Tags:    map[string]string{"service": "people", "action": "list"},
 			KeyColumns: []*plugin.KeyColumn{
 				{
 					Name:    "max_members",
 					Require: plugin.Optional,
 				},
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
 			},
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
 		},
@@ -81,6 +86,7 @@ func tableGoogleWorkspacePeopleContactGroup(_ context.Context) *plugin.Table {
 				Description: "A list of contact person resource names that are members of the contact group.",
 				Type:        proto.ColumnType_JSON,
 			},
+			impersonateUserColumn(),
 		},
 	}
 }
@@ -114,6 +120,9 @@ func listPeopleContactGroups(ctx context.Context, d *plugin.QueryData, _ *plugin
 	var contactGroupNames [][]string
 	resp := service.ContactGroups.List().PageSize(pageLimit)
 	if err := resp.Pages(ctx, func(page *people.ListContactGroupsResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		var resourceNames []string
 		// create a chunk of resourceNames of size 200
 		for _, contactGroup := range page.ContactGroups {
@@ -16,9 +16,16 @@ func tableGoogleWorkspacePeopleDirectoryPeople(_ context.Context) *plugin.Table
 		Description: "Domain contacts in the authenticated user's domain directory.",
 		List: &plugin.ListConfig{
 			Hydrate:           listPeopleDirecoryPeople,
+			Tags:              map[string]string{"service": "people", "action": "list"},
 			ShouldIgnoreError: isNotFoundError([]string{"404"}),
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "impersonate_user",
+					Require: plugin.Optional,
+				},
+			},
 		},
-		Columns: peopleContacts(),
+		Columns: append(peopleContacts(), impersonateUserColumn()),
 	}
 }
 
@@ -46,6 +53,9 @@ func listPeopleDirecoryPeople(ctx context.Context, d *plugin.QueryData, _ *plugi
 
 	resp := service.People.ListDirectoryPeople().ReadMask(personFields).Sources("DIRECTORY_SOURCE_TYPE_DOMAIN_PROFILE").PageSize(maxResult)
 	if err := resp.Pages(ctx, func(page *people.ListDirectoryPeopleResponse) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
 		for _, people := range page.People {
 			// Since, 'names', 'birthdays', 'genders' and 'biographies' are singleton fields
 			var conn contacts
@@ -0,0 +1,131 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceRole(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_role",
+		Description: "Retrieve the administrator roles of the Google Workspace account, including custom roles.",
+		List: &plugin.ListConfig{
+			Hydrate: listRoles,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("role_id"),
+			Hydrate:    getRole,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "role_id",
+				Description: "The unique ID of the role.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("RoleId"),
+			},
+			{
+				Name:        "role_name",
+				Description: "The name of the role.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "role_description",
+				Description: "A short description of the role.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "is_super_admin_role",
+				Description: "Indicates if the role is the super admin role.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsSuperAdminRole"),
+			},
+			{
+				Name:        "is_system_role",
+				Description: "Indicates if the role is a pre-defined system role, as opposed to a custom role.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("IsSystemRole"),
+			},
+			{
+				Name:        "role_privileges",
+				Description: "The privileges granted by the role, with the name of each privilege and the ID of its service.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listRoles(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryRolemanagementReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("nextPageToken,items(roleId,roleName,roleDescription,isSuperAdminRole,isSystemRole,rolePrivileges,etag,kind)")
+
+	req := service.Roles.List(getCustomerID(d)).Fields(fields).MaxResults(100)
+	err = req.Pages(ctx, func(page *admin.Roles) error {
+		// Apply the list rate limiters to each page, before the next one is requested
+		d.WaitForListRateLimit(ctx)
+
+		for _, role := range page.Items {
+			d.StreamListItem(ctx, role)
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				page.NextPageToken = ""
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+//// GET FUNCTION
+
+func getRole(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	roleId := d.EqualsQualString("role_id")
+
+	if roleId == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryRolemanagementReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("roleId,roleName,roleDescription,isSuperAdminRole,isSystemRole,rolePrivileges,etag,kind")
+
+	role, err := service.Roles.Get(getCustomerID(d), roleId).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
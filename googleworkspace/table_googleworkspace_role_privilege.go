@@ -0,0 +1,110 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceRolePrivilege(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_role_privilege",
+		Description: "Retrieve the privileges that can be granted to administrator roles of the Google Workspace account.",
+		List: &plugin.ListConfig{
+			Hydrate: listRolePrivileges,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "privilege_name",
+				Description: "The name of the privilege.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Privilege.PrivilegeName"),
+			},
+			{
+				Name:        "service_id",
+				Description: "The obfuscated ID of the service the privilege is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Privilege.ServiceId"),
+			},
+			{
+				Name:        "service_name",
+				Description: "The name of the service the privilege is for.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Privilege.ServiceName"),
+			},
+			{
+				Name:        "is_ou_scopable",
+				Description: "Indicates if the privilege can be restricted to an organizational unit.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Privilege.IsOuScopable"),
+			},
+			{
+				Name:        "parent_privilege_name",
+				Description: "The name of the privilege this privilege is a child of, if any.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Privilege.Etag"),
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Privilege.Kind"),
+			},
+		},
+	}
+}
+
+// RolePrivilege is a privilege, flattened out of the tree of privileges
+type RolePrivilege struct {
+	Privilege           *admin.Privilege
+	ParentPrivilegeName string
+}
+
+//// LIST FUNCTION
+
+func listRolePrivileges(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryRolemanagementReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := service.Privileges.List(getCustomerID(d)).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the tree of privileges depth first, so that children follow their parent
+	var stream func(privileges []*admin.Privilege, parentPrivilegeName string) bool
+	stream = func(privileges []*admin.Privilege, parentPrivilegeName string) bool {
+		for _, privilege := range privileges {
+			d.StreamListItem(ctx, &RolePrivilege{
+				Privilege:           privilege,
+				ParentPrivilegeName: parentPrivilegeName,
+			})
+
+			// Context can be cancelled due to manual cancellation or the limit has been hit
+			if d.RowsRemaining(ctx) == 0 {
+				return false
+			}
+
+			if !stream(privilege.ChildPrivileges, privilege.PrivilegeName) {
+				return false
+			}
+		}
+		return true
+	}
+	stream(resp.Items, "")
+
+	return nil, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 )
 
@@ -17,10 +18,18 @@ func tableGoogleWorkspaceTokensList(_ context.Context) *plugin.Table {
 		Description: "Retrieve OAuth 2.0 tokens issued to 3rd-party applications for all users in the Google Workspace directory.",
 		List: &plugin.ListConfig{
 			Hydrate: listAllTokens,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
 		},
 		Get: &plugin.GetConfig{
 			KeyColumns: plugin.AllColumns([]string{"user_key", "client_id"}),
 			Hydrate:    getToken,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
 		},
 		Columns: []*plugin.Column{
 			{
@@ -41,6 +50,12 @@ func tableGoogleWorkspaceTokensList(_ context.Context) *plugin.Table {
 				Type:        proto.ColumnType_STRING,
 				Transform:   transform.FromField("PrimaryEmail"),
 			},
+			{
+				Name:        "domain",
+				Description: "The domain of the primary email of the user who authorized the token.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail").Transform(emailDomain),
+			},
 			{
 				Name:        "scopes",
 				Description: "The list of scopes granted to the application.",
@@ -73,6 +88,11 @@ func tableGoogleWorkspaceTokensList(_ context.Context) *plugin.Table {
 				Description: "The ETag of the resource.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "error",
+				Description: "The error returned when listing the tokens of the user, if any. Rows with an error have no token details, and indicate that the tokens of the user are missing from the results.",
+				Type:        proto.ColumnType_STRING,
+			},
 		},
 	}
 }
@@ -88,6 +108,7 @@ type TokenWithUser struct {
 	NativeApp    bool     `json:"native_app"`
 	Kind         string   `json:"kind"`
 	Etag         string   `json:"etag"`
+	Error        string   `json:"error"`
 }
 
 //// LIST FUNCTION
@@ -98,53 +119,59 @@ func listAllTokens(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDa
 		return nil, err
 	}
 
-	// First, get all users
-	userFields := googleapi.Field("users(id,primaryEmail)")
-	usersReq := service.Users.List().Customer("my_customer").Fields(userFields).MaxResults(500)
-
-	usersResp, err := usersReq.Do()
+	// List the tokens of each user concurrently, while paging through the users
+	err = fanOutUsers(ctx, d, service, func(user *admin.User) {
+		listUserTokens(ctx, d, service, user)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Then, for each user, get their tokens
+	return nil, nil
+}
+
+// listUserTokens streams the tokens issued to the given user
+func listUserTokens(ctx context.Context, d *plugin.QueryData, service *admin.Service, user *admin.User) {
 	tokenFields := googleapi.Field("items(clientId,scopes,anonymous,displayText,nativeApp,kind,etag)")
 
-	for _, user := range usersResp.Users {
-		// Check if we should continue processing
-		if d.RowsRemaining(ctx) == 0 {
-			break
+	// Apply the list rate limiters to each call of the fan-out
+	d.WaitForListRateLimit(ctx)
+
+	tokensResp, err := service.Tokens.List(user.PrimaryEmail).Fields(tokenFields).Context(ctx).Do()
+	if err != nil {
+		if isIgnorableFanOutError(err) {
+			return
 		}
 
-		tokensReq := service.Tokens.List(user.PrimaryEmail).Fields(tokenFields)
-		tokensResp, err := tokensReq.Do()
-		if err != nil {
-			// Skip users who don't have tokens or access denied
-			continue
+		// Stream a row with the error, so that the listing is known to be incomplete
+		plugin.Logger(ctx).Warn("googleworkspace_tokens_list.listUserTokens", "user", user.PrimaryEmail, "error", err)
+		d.StreamListItem(ctx, &TokenWithUser{
+			UserKey:      user.PrimaryEmail,
+			PrimaryEmail: user.PrimaryEmail,
+			Error:        err.Error(),
+		})
+		return
+	}
+
+	for _, token := range tokensResp.Items {
+		tokenWithUser := &TokenWithUser{
+			UserKey:      user.PrimaryEmail,
+			PrimaryEmail: user.PrimaryEmail,
+			ClientId:     token.ClientId,
+			Scopes:       token.Scopes,
+			Anonymous:    token.Anonymous,
+			DisplayText:  token.DisplayText,
+			NativeApp:    token.NativeApp,
+			Kind:         token.Kind,
+			Etag:         token.Etag,
 		}
 
-		for _, token := range tokensResp.Items {
-			tokenWithUser := &TokenWithUser{
-				UserKey:      user.PrimaryEmail,
-				PrimaryEmail: user.PrimaryEmail,
-				ClientId:     token.ClientId,
-				Scopes:       token.Scopes,
-				Anonymous:    token.Anonymous,
-				DisplayText:  token.DisplayText,
-				NativeApp:    token.NativeApp,
-				Kind:         token.Kind,
-				Etag:         token.Etag,
-			}
-
-			d.StreamListItem(ctx, tokenWithUser)
-
-			if d.RowsRemaining(ctx) == 0 {
-				break
-			}
+		d.StreamListItem(ctx, tokenWithUser)
+
+		if d.RowsRemaining(ctx) == 0 {
+			return
 		}
 	}
-
-	return nil, nil
 }
 
 //// GET FUNCTION
@@ -0,0 +1,179 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceUserAsp(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_user_asp",
+		Description: "Retrieve the application-specific passwords (ASPs) of users in the Google Workspace directory, which bypass 2-step verification.",
+		List: &plugin.ListConfig{
+			Hydrate: listUserAsps,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "user_key",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "user_key",
+				Description: "The user email or unique ID the ASP belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("UserKey"),
+			},
+			{
+				Name:        "primary_email",
+				Description: "The primary email of the user the ASP belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail"),
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the primary email of the user the ASP belongs to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail").Transform(emailDomain),
+			},
+			{
+				Name:        "code_id",
+				Description: "The unique ID of the ASP.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("CodeId"),
+			},
+			{
+				Name:        "name",
+				Description: "The name of the ASP, as given by the user when creating it.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "creation_time",
+				Description: "The time the ASP was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("CreationTime").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "last_time_used",
+				Description: "The last time the ASP was used, or null if it was never used.",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Transform:   transform.FromField("LastTimeUsed").NullIfZero().Transform(transform.UnixMsToTimestamp),
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The type of the API resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "error",
+				Description: "The error returned when listing the ASPs of the user, if any. Rows with an error have no ASP details, and indicate that the ASPs of the user are missing from the results.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+// AspWithUser combines ASP data with user information
+type AspWithUser struct {
+	UserKey      string
+	PrimaryEmail string
+	CodeId       int64
+	Name         string
+	CreationTime int64
+	LastTimeUsed int64
+	Etag         string
+	Kind         string
+	Error        string
+}
+
+//// LIST FUNCTION
+
+func listUserAsps(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// List the ASPs of the given user only
+	if userKey := d.EqualsQualString("user_key"); userKey != "" {
+		user, err := service.Users.Get(userKey).Fields("id,primaryEmail").Context(ctx).Do()
+		if err != nil {
+			if isNotFoundError([]string{"404"})(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		listAspsOfUser(ctx, d, service, userKey, user)
+		return nil, nil
+	}
+
+	// List the ASPs of each user concurrently, while paging through the users
+	err = fanOutUsers(ctx, d, service, func(user *admin.User) {
+		listAspsOfUser(ctx, d, service, user.PrimaryEmail, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// listAspsOfUser streams the ASPs of the given user
+func listAspsOfUser(ctx context.Context, d *plugin.QueryData, service *admin.Service, userKey string, user *admin.User) {
+	fields := googleapi.Field("items(codeId,name,creationTime,lastTimeUsed,etag,kind)")
+
+	// Apply the list rate limiters to each call of the fan-out
+	d.WaitForListRateLimit(ctx)
+
+	resp, err := service.Asps.List(user.PrimaryEmail).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		if isIgnorableFanOutError(err) {
+			return
+		}
+
+		// Stream a row with the error, so that the listing is known to be incomplete
+		plugin.Logger(ctx).Warn("googleworkspace_user_asp.listAspsOfUser", "user", user.PrimaryEmail, "error", err)
+		d.StreamListItem(ctx, &AspWithUser{
+			UserKey:      userKey,
+			PrimaryEmail: user.PrimaryEmail,
+			Error:        err.Error(),
+		})
+		return
+	}
+
+	for _, asp := range resp.Items {
+		d.StreamListItem(ctx, &AspWithUser{
+			UserKey:      userKey,
+			PrimaryEmail: user.PrimaryEmail,
+			CodeId:       asp.CodeId,
+			Name:         asp.Name,
+			CreationTime: asp.CreationTime,
+			LastTimeUsed: asp.LastTimeUsed,
+			Etag:         asp.Etag,
+			Kind:         asp.Kind,
+		})
+
+		if d.RowsRemaining(ctx) == 0 {
+			return
+		}
+	}
+}
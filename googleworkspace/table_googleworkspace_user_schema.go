@@ -0,0 +1,111 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceUserSchema(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_user_schema",
+		Description: "Retrieve the custom user schemas defined in the Google Workspace directory.",
+		List: &plugin.ListConfig{
+			Hydrate: listUserSchemas,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("schema_name"),
+			Hydrate:    getUserSchema,
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "schema_name",
+				Description: "The name of the schema, as used in the custom_schemas of users.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "schema_id",
+				Description: "The unique ID of the schema.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "display_name",
+				Description: "The display name of the schema.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "table_name",
+				Description: "The name of the dynamic table listing the values of the schema for each user.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("SchemaName").Transform(userSchemaTableNameTransform),
+			},
+			{
+				Name:        "fields",
+				Description: "The fields of the schema, including their name, type and whether they are indexed or multi-valued.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "etag",
+				Description: "The ETag of the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+//// LIST FUNCTION
+
+func listUserSchemas(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryUserschemaReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := service.Schemas.List(getCustomerID(d)).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schema := range resp.Schemas {
+		d.StreamListItem(ctx, schema)
+
+		// Context can be cancelled due to manual cancellation or the limit has been hit
+		if d.RowsRemaining(ctx) == 0 {
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+//// HYDRATE FUNCTIONS
+
+func getUserSchema(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	schemaName := d.EqualsQualString("schema_name")
+
+	if schemaName == "" {
+		return nil, nil
+	}
+
+	service, err := adminServiceWithScopes(ctx, d, admin.AdminDirectoryUserschemaReadonlyScope)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := googleapi.Field("schemaId,schemaName,displayName,fields,etag")
+
+	schema, err := service.Schemas.Get(getCustomerID(d), schemaName).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,379 @@
+package googleworkspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// Prefix of the dynamic tables listing the values of each custom user schema
+const userSchemaTablePrefix = "googleworkspace_user_schema_"
+
+// Columns of every custom user schema table. Fields with the same name are prefixed with `field_`.
+var userSchemaFixedColumns = []string{"user_id", "primary_email"}
+
+// userSchemaRow is a row of a custom user schema table, holding the values of a user
+// keyed by column name
+type userSchemaRow struct {
+	UserId       string
+	PrimaryEmail string
+	Values       map[string]interface{}
+}
+
+// userSchemaColumn maps a column of a custom user schema table to a field of the schema
+type userSchemaColumn struct {
+	name  string
+	field *admin.SchemaFieldSpec
+}
+
+//// TABLE DEFINITION
+
+// tablesGoogleWorkspaceUserSchemas returns one table per custom user schema of the customer.
+// Schemas cannot be listed without the userschema scope, in which case no table is returned.
+func tablesGoogleWorkspaceUserSchemas(ctx context.Context, d *plugin.TableMapData) map[string]*plugin.Table {
+	tables := map[string]*plugin.Table{}
+
+	// The services are built from query data, so build one for the connection being loaded
+	queryData := &plugin.QueryData{
+		Connection:        d.Connection,
+		ConnectionManager: connection.NewManager(d.ConnectionCache),
+	}
+
+	service, err := adminServiceWithScopes(ctx, queryData, admin.AdminDirectoryUserschemaReadonlyScope)
+	if err != nil {
+		plugin.Logger(ctx).Warn("tablesGoogleWorkspaceUserSchemas", "service_creation_error", err)
+		return tables
+	}
+
+	resp, err := service.Schemas.List(getCustomerID(queryData)).Context(ctx).Do()
+	if err != nil {
+		plugin.Logger(ctx).Warn("tablesGoogleWorkspaceUserSchemas", "api_error", err)
+		return tables
+	}
+
+	for _, schema := range resp.Schemas {
+		tables[userSchemaTableName(schema.SchemaName)] = tableGoogleWorkspaceUserSchemaDynamic(ctx, schema)
+	}
+
+	return tables
+}
+
+func tableGoogleWorkspaceUserSchemaDynamic(ctx context.Context, schema *admin.Schema) *plugin.Table {
+	columns := []*plugin.Column{
+		{
+			Name:        "user_id",
+			Description: "The unique ID of the user.",
+			Type:        proto.ColumnType_STRING,
+		},
+		{
+			Name:        "primary_email",
+			Description: "The user's primary email address.",
+			Type:        proto.ColumnType_STRING,
+		},
+	}
+	keyColumns := []*plugin.KeyColumn{}
+
+	schemaColumns := userSchemaColumns(ctx, schema)
+	for _, column := range schemaColumns {
+		description := column.field.DisplayName
+		if description == "" {
+			description = column.field.FieldName
+		}
+		columns = append(columns, &plugin.Column{
+			Name:        column.name,
+			Description: fmt.Sprintf("%s (%s.%s).", description, schema.SchemaName, column.field.FieldName),
+			Type:        userSchemaColumnType(column.field),
+			Transform:   transform.FromP(userSchemaValue, column.name),
+		})
+
+		// Indexed fields can be searched on
+		if userSchemaFieldSearchable(column.field) {
+			keyColumns = append(keyColumns, &plugin.KeyColumn{
+				Name:    column.name,
+				Require: plugin.Optional,
+			})
+		}
+	}
+
+	description := schema.DisplayName
+	if description == "" {
+		description = schema.SchemaName
+	}
+
+	return &plugin.Table{
+		Name:        userSchemaTableName(schema.SchemaName),
+		Description: fmt.Sprintf("Retrieve the values of the %s custom user schema for each user in the Google Workspace directory.", description),
+		List: &plugin.ListConfig{
+			Hydrate:    listUserSchemaValues(schema.SchemaName, schemaColumns),
+			Tags:       map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: keyColumns,
+		},
+		Get: &plugin.GetConfig{
+			KeyColumns: plugin.SingleColumn("user_id"),
+			Hydrate:    getUserSchemaValues(schema.SchemaName, schemaColumns),
+			Tags:       map[string]string{"service": "admin", "action": "get"},
+		},
+		Columns: columns,
+	}
+}
+
+//// LIST FUNCTION
+
+func listUserSchemaValues(schemaName string, columns []userSchemaColumn) plugin.HydrateFunc {
+	return func(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+		service, err := AdminService(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter on the searchable fields of the schema
+		var terms []string
+		for _, column := range columns {
+			if value := d.EqualsQuals[column.name]; value != nil && userSchemaFieldSearchable(column.field) {
+				terms = append(terms, fmt.Sprintf("%s.%s=%s", schemaName, column.field.FieldName, userSchemaSearchValue(value)))
+			}
+		}
+
+		fields := googleapi.Field("nextPageToken,users(id,primaryEmail,customSchemas)")
+
+		for _, domain := range getDomains(d) {
+			resp := service.Users.List().Fields(fields).MaxResults(500).Projection("custom").CustomFieldMask(schemaName)
+
+			// List users of the given domain, or of all domains of the customer
+			if domain != "" {
+				resp = resp.Domain(domain)
+			} else {
+				resp = resp.Customer(getCustomerID(d))
+			}
+
+			if len(terms) > 0 {
+				resp = resp.Query(strings.Join(terms, " "))
+			}
+
+			err = resp.Pages(ctx, func(page *admin.Users) error {
+				// Apply the list rate limiters to each page, before the next one is requested
+				d.WaitForListRateLimit(ctx)
+
+				for _, user := range page.Users {
+					row, err := buildUserSchemaRow(user, schemaName, columns)
+					if err != nil {
+						return err
+					}
+					// Only list the users that have values for the schema
+					if row == nil {
+						continue
+					}
+
+					d.StreamListItem(ctx, row)
+
+					if d.RowsRemaining(ctx) == 0 {
+						page.NextPageToken = ""
+						return nil
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				plugin.Logger(ctx).Error("googleworkspace_user_schema.listUserSchemaValues", "schema", schemaName, "api_error", err)
+				return nil, err
+			}
+
+			if d.RowsRemaining(ctx) == 0 {
+				break
+			}
+		}
+
+		return nil, nil
+	}
+}
+
+//// HYDRATE FUNCTIONS
+
+func getUserSchemaValues(schemaName string, columns []userSchemaColumn) plugin.HydrateFunc {
+	return func(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+		userId := d.EqualsQualString("user_id")
+
+		if userId == "" {
+			return nil, nil
+		}
+
+		service, err := AdminService(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err := service.Users.Get(userId).Fields("id,primaryEmail,customSchemas").Projection("custom").CustomFieldMask(schemaName).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+
+		row, err := buildUserSchemaRow(user, schemaName, columns)
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return nil, nil
+		}
+
+		return row, nil
+	}
+}
+
+// buildUserSchemaRow returns the values of the given schema for a user, converted to the type of
+// their columns, or nil if the user has no values for the schema
+func buildUserSchemaRow(user *admin.User, schemaName string, columns []userSchemaColumn) (*userSchemaRow, error) {
+	raw, ok := user.CustomSchemas[schemaName]
+	if !ok {
+		return nil, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse custom schema %s of user %s: %v", schemaName, user.Id, err)
+	}
+
+	row := &userSchemaRow{
+		UserId:       user.Id,
+		PrimaryEmail: user.PrimaryEmail,
+		Values:       map[string]interface{}{},
+	}
+	for _, column := range columns {
+		value, ok := fields[column.field.FieldName]
+		if !ok {
+			continue
+		}
+		// Multi-valued fields are returned as a list of objects with a value, and a type
+		if column.field.MultiValued {
+			row.Values[column.name] = value
+			continue
+		}
+		converted, err := convertUserSchemaValue(column.field.FieldType, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field %s.%s of user %s: %v", schemaName, column.field.FieldName, user.Id, err)
+		}
+		row.Values[column.name] = converted
+	}
+
+	return row, nil
+}
+
+// userSchemaTableName returns the name of the dynamic table of the given custom user schema
+func userSchemaTableName(schemaName string) string {
+	return userSchemaTablePrefix + strcase.ToSnake(schemaName)
+}
+
+// userSchemaColumns returns the columns of the fields of the given schema. Fields are named after
+// the field, in snake case, prefixed with `field_` if it would clash with another column.
+func userSchemaColumns(ctx context.Context, schema *admin.Schema) []userSchemaColumn {
+	var columns []userSchemaColumn
+	names := slices.Clone(userSchemaFixedColumns)
+
+	for _, field := range schema.Fields {
+		name := strcase.ToSnake(field.FieldName)
+		if slices.Contains(names, name) {
+			name = "field_" + name
+		}
+		if slices.Contains(names, name) {
+			plugin.Logger(ctx).Warn("userSchemaColumns", "schema", schema.SchemaName, "duplicate_field", field.FieldName)
+			continue
+		}
+		names = append(names, name)
+		columns = append(columns, userSchemaColumn{name: name, field: field})
+	}
+
+	return columns
+}
+
+// userSchemaColumnType returns the column type of a custom schema field
+func userSchemaColumnType(field *admin.SchemaFieldSpec) proto.ColumnType {
+	if field.MultiValued {
+		return proto.ColumnType_JSON
+	}
+
+	switch field.FieldType {
+	case "BOOL":
+		return proto.ColumnType_BOOL
+	case "INT64":
+		return proto.ColumnType_INT
+	case "DOUBLE":
+		return proto.ColumnType_DOUBLE
+	case "DATE":
+		return proto.ColumnType_TIMESTAMP
+	default:
+		// STRING, EMAIL and PHONE
+		return proto.ColumnType_STRING
+	}
+}
+
+// userSchemaFieldSearchable returns true if the given field can be filtered on using an exact
+// match in a user search query
+func userSchemaFieldSearchable(field *admin.SchemaFieldSpec) bool {
+	if field.Indexed == nil || !*field.Indexed || field.MultiValued {
+		return false
+	}
+	return slices.Contains([]string{"STRING", "EMAIL", "PHONE", "BOOL", "INT64"}, field.FieldType)
+}
+
+// convertUserSchemaValue converts the JSON value of a single-valued field to the type of its column.
+// Numbers may be returned as JSON strings, so they are parsed if needed.
+func convertUserSchemaValue(fieldType string, value interface{}) (interface{}, error) {
+	s, isString := value.(string)
+	if !isString {
+		return value, nil
+	}
+
+	switch fieldType {
+	case "BOOL":
+		return strconv.ParseBool(s)
+	case "INT64":
+		return strconv.ParseInt(s, 10, 64)
+	case "DOUBLE":
+		return strconv.ParseFloat(s, 64)
+	case "DATE":
+		return time.Parse(time.DateOnly, s)
+	}
+
+	return s, nil
+}
+
+// userSchemaSearchValue returns the value of a qual on a custom schema field, as used in a search query
+func userSchemaSearchValue(value *proto.QualValue) string {
+	switch v := value.Value.(type) {
+	case *proto.QualValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *proto.QualValue_Int64Value:
+		return strconv.FormatInt(v.Int64Value, 10)
+	default:
+		return quoteSearchValue(value.GetStringValue())
+	}
+}
+
+//// TRANSFORM FUNCTIONS
+
+// userSchemaValue returns the value of the column given as the transform param
+func userSchemaValue(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	row := d.HydrateItem.(*userSchemaRow)
+	return row.Values[types.SafeString(d.Param)], nil
+}
+
+// userSchemaTableNameTransform returns the name of the dynamic table of a custom user schema
+func userSchemaTableNameTransform(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	schemaName := types.SafeString(d.Value)
+	if schemaName == "" {
+		return nil, nil
+	}
+	return userSchemaTableName(schemaName), nil
+}
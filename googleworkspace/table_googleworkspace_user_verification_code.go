@@ -0,0 +1,138 @@
+package googleworkspace
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+//// TABLE DEFINITION
+
+func tableGoogleWorkspaceUserVerificationCode(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "googleworkspace_user_verification_code",
+		Description: "Retrieve the number of unused backup verification codes of users in the Google Workspace directory. The codes themselves are never retrieved.",
+		List: &plugin.ListConfig{
+			Hydrate: listUserVerificationCodes,
+			Tags:    map[string]string{"service": "admin", "action": "list"},
+			KeyColumns: []*plugin.KeyColumn{
+				{
+					Name:    "user_key",
+					Require: plugin.Optional,
+				},
+				{
+					Name:    "domain",
+					Require: plugin.Optional,
+				},
+			},
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "user_key",
+				Description: "The user email or unique ID the verification codes belong to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("UserKey"),
+			},
+			{
+				Name:        "primary_email",
+				Description: "The primary email of the user the verification codes belong to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail"),
+			},
+			{
+				Name:        "domain",
+				Description: "The domain of the primary email of the user the verification codes belong to.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("PrimaryEmail").Transform(emailDomain),
+			},
+			{
+				Name:        "verification_code_count",
+				Description: "The number of unused backup verification codes of the user.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("VerificationCodeCount"),
+			},
+			{
+				Name:        "error",
+				Description: "The error returned when listing the verification codes of the user, if any. Rows with an error have no count.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+// UserVerificationCodes holds the number of verification codes of a user
+type UserVerificationCodes struct {
+	UserKey               string
+	PrimaryEmail          string
+	VerificationCodeCount *int64
+	Error                 string
+}
+
+//// LIST FUNCTION
+
+func listUserVerificationCodes(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	service, err := AdminService(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the verification codes of the given user only
+	if userKey := d.EqualsQualString("user_key"); userKey != "" {
+		user, err := service.Users.Get(userKey).Fields("id,primaryEmail").Context(ctx).Do()
+		if err != nil {
+			if isNotFoundError([]string{"404"})(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		countVerificationCodesOfUser(ctx, d, service, userKey, user)
+		return nil, nil
+	}
+
+	// Count the verification codes of each user concurrently, while paging through the users
+	err = fanOutUsers(ctx, d, service, func(user *admin.User) {
+		countVerificationCodesOfUser(ctx, d, service, user.PrimaryEmail, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// countVerificationCodesOfUser streams the number of verification codes of the given user
+func countVerificationCodesOfUser(ctx context.Context, d *plugin.QueryData, service *admin.Service, userKey string, user *admin.User) {
+	// Request no field of the codes, so that the codes themselves are never sent
+	fields := googleapi.Field("items(kind)")
+
+	// Apply the list rate limiters to each call of the fan-out
+	d.WaitForListRateLimit(ctx)
+
+	resp, err := service.VerificationCodes.List(user.PrimaryEmail).Fields(fields).Context(ctx).Do()
+	if err != nil {
+		if isIgnorableFanOutError(err) {
+			return
+		}
+
+		// Stream a row with the error, so that the listing is known to be incomplete
+		plugin.Logger(ctx).Warn("googleworkspace_user_verification_code.countVerificationCodesOfUser", "user", user.PrimaryEmail, "error", err)
+		d.StreamListItem(ctx, &UserVerificationCodes{
+			UserKey:      userKey,
+			PrimaryEmail: user.PrimaryEmail,
+			Error:        err.Error(),
+		})
+		return
+	}
+
+	count := int64(len(resp.Items))
+	d.StreamListItem(ctx, &UserVerificationCodes{
+		UserKey:               userKey,
+		PrimaryEmail:          user.PrimaryEmail,
+		VerificationCodeCount: &count,
+	})
+}
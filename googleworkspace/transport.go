@@ -0,0 +1,75 @@
+package googleworkspace
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// getBaseTransport returns the transport used for every request made by the connection, including
+// token requests, with the http_proxy and ca_bundle configured for the connection applied
+func getBaseTransport(d *plugin.QueryData) (*http.Transport, error) {
+	// have we already created and cached the transport? Sharing it across services reuses connections.
+	cacheKey := "googleworkspace.transport"
+	if cachedData, ok := d.ConnectionManager.Cache.Get(cacheKey); ok {
+		return cachedData.(*http.Transport), nil
+	}
+
+	googleworkspaceConfig := GetConfig(d.Connection)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// Use the configured proxy, instead of the HTTP_PROXY and HTTPS_PROXY environment variables
+	if googleworkspaceConfig.HTTPProxy != nil && *googleworkspaceConfig.HTTPProxy != "" {
+		proxyURL, err := url.Parse(*googleworkspaceConfig.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http_proxy: %v", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid http_proxy %q: must be an absolute URL, such as http://proxy.example.com:3128", *googleworkspaceConfig.HTTPProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// Trust the configured CA certificates, in addition to the system ones
+	if googleworkspaceConfig.CABundle != nil && *googleworkspaceConfig.CABundle != "" {
+		bundle, err := pathOrContents(*googleworkspaceConfig.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_bundle: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(bundle)) {
+			return nil, errors.New("invalid ca_bundle: no PEM encoded certificates found")
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	// cache the transport
+	d.ConnectionManager.Cache.Set(cacheKey, transport)
+
+	return transport, nil
+}
+
+// withBaseTransport returns a context that makes the OAuth 2.0 token sources created with it
+// send their token requests using the base transport of the connection
+func withBaseTransport(ctx context.Context, d *plugin.QueryData) (context.Context, error) {
+	transport, err := getBaseTransport(d)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport}), nil
+}
@@ -1,10 +1,16 @@
 package googleworkspace
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
+	"github.com/turbot/go-kit/types"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 // Returns the content of given file, or the inline JSON credential as it is
@@ -49,3 +55,34 @@ func expandPath(filePath string) (string, error) {
 	}
 	return path, nil
 }
+
+// quoteSearchValue quotes a value for use in an Admin SDK search query, escaping any single
+// quotes and backslashes in it
+func quoteSearchValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// impersonateUserColumn returns the optional column used to query the data of another user,
+// by impersonating them using domain-wide delegation
+func impersonateUserColumn() *plugin.Column {
+	return &plugin.Column{
+		Name:        "impersonate_user",
+		Description: "The email of the user to impersonate using domain-wide delegation. If not specified, the impersonated_user_email configured for the connection is used.",
+		Type:        proto.ColumnType_STRING,
+		Transform:   transform.FromQual("impersonate_user"),
+	}
+}
+
+//// TRANSFORM FUNCTIONS
+
+// emailDomain returns the domain part of an email address
+func emailDomain(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	email := types.SafeString(d.Value)
+
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return nil, nil
+	}
+
+	return email[i+1:], nil
+}
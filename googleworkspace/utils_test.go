@@ -0,0 +1,23 @@
+package googleworkspace
+
+import "testing"
+
+func TestQuoteSearchValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Jane", "'Jane'"},
+		{"Jane Doe", "'Jane Doe'"},
+		{"O'Brien", `'O\'Brien'`},
+		{`back\slash`, `'back\\slash'`},
+		{`\'`, `'\\\''`},
+		{"", "''"},
+	}
+
+	for _, tt := range tests {
+		if got := quoteSearchValue(tt.value); got != tt.want {
+			t.Errorf("quoteSearchValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}